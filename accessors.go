@@ -0,0 +1,447 @@
+package insaneJSON
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// hasValue checks if node has a value which As*() functions can convert,
+// nil, null, objects and arrays have no value
+func (n *Node) hasValue() bool {
+	return n != nil && n.bits&(hellBitString|hellBitEscapedString|hellBitNumber|hellBitTrue|hellBitFalse|hellBitField) != 0
+}
+
+// hasNumber checks if node value can be converted to a number without losing it
+func (n *Node) hasNumber() bool {
+	if !n.hasValue() {
+		return false
+	}
+
+	if n.bits&hellBitTypeFilter == hellBitEscapedString {
+		n.unescapeStr()
+	}
+
+	if n.bits&(hellBitTrue|hellBitFalse) != 0 {
+		return true
+	}
+
+	return isNumber(n.data)
+}
+
+// TryInt returns the same value as AsInt() and false if node is missing, it can't be converted to a number or int overflows
+func (n *Node) TryInt() (int, bool) {
+	n.debugCheck()
+
+	if !n.hasNumber() || !n.fitsInt(true, strconv.IntSize) {
+		return 0, false
+	}
+
+	return n.AsInt(), true
+}
+
+// TryInt64 returns the same value as AsInt64() and false if node is missing, it can't be converted to a number,
+// int64 overflows or number has exponent, since AsInt64() doesn't parse it
+func (n *Node) TryInt64() (int64, bool) {
+	n.debugCheck()
+
+	if !n.hasNumber() || !n.fitsInt(false, 64) {
+		return 0, false
+	}
+
+	return n.AsInt64(), true
+}
+
+// TryUint64 returns the same value as AsUint64() and false if node is missing, it can't be converted to a number,
+// it's negative, uint64 overflows or number has exponent, since AsUint64() doesn't parse it
+func (n *Node) TryUint64() (uint64, bool) {
+	n.debugCheck()
+
+	if !n.hasNumber() || !n.fitsUint() {
+		return 0, false
+	}
+
+	return n.AsUint64(), true
+}
+
+// TryFloat returns the same value as AsFloat() and false if node is missing or it can't be converted to a number
+func (n *Node) TryFloat() (float64, bool) {
+	n.debugCheck()
+
+	if !n.hasNumber() {
+		return 0, false
+	}
+
+	return n.AsFloat(), true
+}
+
+// TryString returns the same value as AsString() and false if node is missing, null, object or array
+func (n *Node) TryString() (string, bool) {
+	n.debugCheck()
+
+	if !n.hasValue() {
+		return "", false
+	}
+
+	return n.AsString(), true
+}
+
+// TryBytes returns the same value as AsBytes() and false if node is missing, null, object or array
+func (n *Node) TryBytes() ([]byte, bool) {
+	n.debugCheck()
+
+	if !n.hasValue() {
+		return nil, false
+	}
+
+	return n.AsBytes(), true
+}
+
+// TryBool returns the same value as AsBool() and false if node is missing or it isn't a bool, a number or "true"/"false" string
+func (n *Node) TryBool() (bool, bool) {
+	n.debugCheck()
+
+	if !n.hasValue() {
+		return false, false
+	}
+
+	if n.bits&hellBitTypeFilter == hellBitEscapedString {
+		n.unescapeStr()
+	}
+
+	switch n.bits & hellBitTypeFilter {
+	case hellBitString, hellBitField:
+		if n.data != "true" && n.data != "false" {
+			return false, false
+		}
+	case hellBitNumber:
+		if !isNumber(n.data) {
+			return false, false
+		}
+	}
+
+	return n.AsBool(), true
+}
+
+// IntOr returns AsInt() or def if node is missing or it can't be converted to a number
+func (n *Node) IntOr(def int) int {
+	n.debugCheck()
+
+	if value, ok := n.TryInt(); ok {
+		return value
+	}
+
+	return def
+}
+
+// Int64Or returns AsInt64() or def if node is missing or it can't be converted to a number
+func (n *Node) Int64Or(def int64) int64 {
+	n.debugCheck()
+
+	if value, ok := n.TryInt64(); ok {
+		return value
+	}
+
+	return def
+}
+
+// Uint64Or returns AsUint64() or def if node is missing or it can't be converted to a number
+func (n *Node) Uint64Or(def uint64) uint64 {
+	n.debugCheck()
+
+	if value, ok := n.TryUint64(); ok {
+		return value
+	}
+
+	return def
+}
+
+// FloatOr returns AsFloat() or def if node is missing or it can't be converted to a number
+func (n *Node) FloatOr(def float64) float64 {
+	n.debugCheck()
+
+	if value, ok := n.TryFloat(); ok {
+		return value
+	}
+
+	return def
+}
+
+// StringOr returns AsString() or def if node is missing, null, object or array
+func (n *Node) StringOr(def string) string {
+	n.debugCheck()
+
+	if value, ok := n.TryString(); ok {
+		return value
+	}
+
+	return def
+}
+
+// BytesOr returns AsBytes() or def if node is missing, null, object or array
+func (n *Node) BytesOr(def []byte) []byte {
+	n.debugCheck()
+
+	if value, ok := n.TryBytes(); ok {
+		return value
+	}
+
+	return def
+}
+
+// BoolOr returns AsBool() or def if node is missing or it can't be converted to a bool
+func (n *Node) BoolOr(def bool) bool {
+	n.debugCheck()
+
+	if value, ok := n.TryBool(); ok {
+		return value
+	}
+
+	return def
+}
+
+// fitsInt checks if number of node is parsed by AsInt() or AsInt64() without overflow,
+// exp is true if numbers with exponent are rounded like ones with fraction
+func (n *Node) fitsInt(exp bool, bitSize int) bool {
+	if n.bits&(hellBitTrue|hellBitFalse) != 0 {
+		return true
+	}
+
+	if strings.IndexByte(n.data, '.') != -1 || (exp && strings.IndexAny(n.data, "eE") != -1) {
+		limit := math.Ldexp(1, bitSize-1)
+		x := math.Round(decodeFloat64(n.data))
+		return x >= -limit && x < limit
+	}
+
+	_, err := strconv.ParseInt(n.data, 10, bitSize)
+	return err == nil
+}
+
+// fitsUint checks if number of node is parsed by AsUint64() without overflow, -0 is allowed
+func (n *Node) fitsUint() bool {
+	if n.bits&(hellBitTrue|hellBitFalse) != 0 {
+		return true
+	}
+
+	if strings.IndexByte(n.data, '.') != -1 {
+		x := math.Round(decodeFloat64(n.data))
+		return x >= 0 && x < math.Ldexp(1, 64)
+	}
+
+	if strings.HasPrefix(n.data, "-") {
+		x, err := strconv.ParseInt(n.data, 10, 64)
+		return err == nil && x == 0
+	}
+
+	_, err := strconv.ParseUint(n.data, 10, 64)
+	return err == nil
+}
+
+// isNumber checks if s is a valid JSON number
+func isNumber(s string) bool {
+	l := len(s)
+	o := 0
+	if o < l && s[o] == '-' {
+		o++
+	}
+	if o == l {
+		return false
+	}
+
+	if s[o] == '0' {
+		o++
+	} else {
+		t := o
+		for o < l && s[o] >= '0' && s[o] <= '9' {
+			o++
+		}
+		if t == o {
+			return false
+		}
+	}
+
+	if o < l && s[o] == '.' {
+		o++
+		t := o
+		for o < l && s[o] >= '0' && s[o] <= '9' {
+			o++
+		}
+		if t == o {
+			return false
+		}
+	}
+
+	if o < l && (s[o] == 'e' || s[o] == 'E') {
+		o++
+		if o < l && (s[o] == '+' || s[o] == '-') {
+			o++
+		}
+		t := o
+		for o < l && s[o] >= '0' && s[o] <= '9' {
+			o++
+		}
+		if t == o {
+			return false
+		}
+	}
+
+	return o == l
+}
+
+func (n *StrictNode) AsInt8() (int8, error) {
+	n.debugCheck()
+
+	num, err := n.asInt(8)
+	return int8(num), err
+}
+
+func (n *StrictNode) AsInt16() (int16, error) {
+	n.debugCheck()
+
+	num, err := n.asInt(16)
+	return int16(num), err
+}
+
+func (n *StrictNode) AsInt32() (int32, error) {
+	n.debugCheck()
+
+	num, err := n.asInt(32)
+	return int32(num), err
+}
+
+func (n *StrictNode) AsUint8() (uint8, error) {
+	n.debugCheck()
+
+	num, err := n.asUint(8)
+	return uint8(num), err
+}
+
+func (n *StrictNode) AsUint16() (uint16, error) {
+	n.debugCheck()
+
+	num, err := n.asUint(16)
+	return uint16(num), err
+}
+
+func (n *StrictNode) AsUint32() (uint32, error) {
+	n.debugCheck()
+
+	num, err := n.asUint(32)
+	return uint32(num), err
+}
+
+func (n *StrictNode) AsUint() (uint, error) {
+	n.debugCheck()
+
+	num, err := n.asUint(strconv.IntSize)
+	return uint(num), err
+}
+
+// asInt returns ErrNotInteger for numbers with fraction or exponent and ErrNumberOverflow if number doesn't fit bitSize
+func (n *StrictNode) asInt(bitSize int) (int64, error) {
+	if n == nil || n.Node == nil || n.bits&hellBitNumber != hellBitNumber || !isNumber(n.data) {
+		return 0, ErrNotNumber
+	}
+
+	num, err := strconv.ParseInt(n.data, 10, bitSize)
+	if err != nil {
+		return 0, numberErr(err)
+	}
+
+	return num, nil
+}
+
+// asUint returns ErrNumberOverflow for negative numbers except -0
+func (n *StrictNode) asUint(bitSize int) (uint64, error) {
+	if n == nil || n.Node == nil || n.bits&hellBitNumber != hellBitNumber || !isNumber(n.data) {
+		return 0, ErrNotNumber
+	}
+
+	if strings.HasPrefix(n.data, "-") {
+		num, err := strconv.ParseInt(n.data, 10, 64)
+		if err != nil {
+			return 0, numberErr(err)
+		}
+		if num != 0 {
+			return 0, ErrNumberOverflow
+		}
+		return 0, nil
+	}
+
+	num, err := strconv.ParseUint(n.data, 10, bitSize)
+	if err != nil {
+		return 0, numberErr(err)
+	}
+
+	return num, nil
+}
+
+// numberErr converts strconv error of parsing a valid JSON number
+func numberErr(err error) error {
+	if e, ok := err.(*strconv.NumError); ok && e.Err == strconv.ErrRange {
+		return ErrNumberOverflow
+	}
+
+	return ErrNotInteger
+}
+
+// isExactFloat checks if number literal s is exactly equal to f
+func isExactFloat(s string, f float64) bool {
+	buf := [32]byte{}
+	digits, exp, ok := significantDigits(s, buf[:0])
+	if !ok {
+		return false
+	}
+
+	fStr := [32]byte{}
+	fBuf := [32]byte{}
+	fDigits, fExp, _ := significantDigits(toString(strconv.AppendFloat(fStr[:0], f, 'e', -1, 64)), fBuf[:0])
+
+	return exp == fExp && string(digits) == string(fDigits)
+}
+
+// significantDigits returns digits of number literal s without leading and trailing zeros,
+// so s equals to digits*10^exp, ok is false if digits don't fit buf capacity
+func significantDigits(s string, buf []byte) ([]byte, int, bool) {
+	digits := buf
+	exp := 0
+	zeros := 0
+	frac := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			if frac {
+				exp--
+			}
+			if c == '0' {
+				if len(digits) != 0 {
+					zeros++
+				}
+				continue
+			}
+			if len(digits)+zeros+1 > cap(digits) {
+				return digits, 0, false
+			}
+			for ; zeros > 0; zeros-- {
+				digits = append(digits, '0')
+			}
+			digits = append(digits, c)
+		case c == '.':
+			frac = true
+		case c == 'e' || c == 'E':
+			e, err := strconv.Atoi(s[i+1:])
+			if err != nil {
+				return digits, 0, false
+			}
+			exp += e
+			i = len(s)
+		}
+	}
+
+	if len(digits) == 0 {
+		return digits, 0, true
+	}
+
+	return digits, exp + zeros, true
+}
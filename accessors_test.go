@@ -0,0 +1,216 @@
+package insaneJSON
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTryAccessors(t *testing.T) {
+	json := `{"zero":0,"int":5,"float":5.6,"str_int":"7","str":"insane","true":true,"false":false,"null":null,"obj":{},"arr":[],"esc":"\"x\"","str_bool":"true"}`
+	root, err := DecodeString(json)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	tests := []struct {
+		path string
+
+		int       int
+		intOk     bool
+		float     float64
+		floatOk   bool
+		str       string
+		strOk     bool
+		boolean   bool
+		booleanOk bool
+	}{
+		{path: "zero", int: 0, intOk: true, float: 0, floatOk: true, str: "0", strOk: true, boolean: false, booleanOk: true},
+		{path: "int", int: 5, intOk: true, float: 5, floatOk: true, str: "5", strOk: true, boolean: true, booleanOk: true},
+		{path: "float", int: 6, intOk: true, float: 5.6, floatOk: true, str: "5.6", strOk: true, boolean: true, booleanOk: true},
+		{path: "str_int", int: 7, intOk: true, float: 7, floatOk: true, str: "7", strOk: true},
+		{path: "str", str: "insane", strOk: true},
+		{path: "true", int: 1, intOk: true, float: 1, floatOk: true, str: "true", strOk: true, boolean: true, booleanOk: true},
+		{path: "false", int: 0, intOk: true, float: 0, floatOk: true, str: "false", strOk: true, boolean: false, booleanOk: true},
+		{path: "str_bool", str: "true", strOk: true, boolean: true, booleanOk: true},
+		{path: "esc", str: `"x"`, strOk: true},
+		{path: "null"},
+		{path: "obj"},
+		{path: "arr"},
+		{path: "missing"},
+	}
+
+	for _, test := range tests {
+		node := root.Dig(test.path)
+
+		i, ok := node.TryInt()
+		assert.Equal(t, test.int, i, "wrong int for %s", test.path)
+		assert.Equal(t, test.intOk, ok, "wrong int presence for %s", test.path)
+
+		f, ok := node.TryFloat()
+		assert.Equal(t, test.float, f, "wrong float for %s", test.path)
+		assert.Equal(t, test.floatOk, ok, "wrong float presence for %s", test.path)
+
+		s, ok := node.TryString()
+		assert.Equal(t, test.str, s, "wrong string for %s", test.path)
+		assert.Equal(t, test.strOk, ok, "wrong string presence for %s", test.path)
+
+		b, ok := node.TryBool()
+		assert.Equal(t, test.boolean, b, "wrong bool for %s", test.path)
+		assert.Equal(t, test.booleanOk, ok, "wrong bool presence for %s", test.path)
+	}
+
+	assert.Equal(t, 0, root.Dig("zero").IntOr(-1), "wrong value")
+	assert.Equal(t, -1, root.Dig("missing").IntOr(-1), "wrong default")
+	assert.Equal(t, -1, root.Dig("str").IntOr(-1), "wrong default")
+	assert.Equal(t, int64(5), root.Dig("int").Int64Or(-1), "wrong value")
+	assert.Equal(t, uint64(9), root.Dig("null").Uint64Or(9), "wrong default")
+	assert.Equal(t, 1.5, root.Dig("obj").FloatOr(1.5), "wrong default")
+	assert.Equal(t, "def", root.Dig("arr").StringOr("def"), "wrong default")
+	assert.Equal(t, "insane", root.Dig("str").StringOr("def"), "wrong value")
+	assert.Equal(t, []byte("def"), root.Dig("null").BytesOr([]byte("def")), "wrong default")
+	assert.Equal(t, true, root.Dig("str").BoolOr(true), "wrong default")
+	assert.Equal(t, false, root.Dig("false").BoolOr(true), "wrong value")
+}
+
+func TestTryIntegers(t *testing.T) {
+	json := `{"int":5,"neg":-5,"neg_zero":-0,"str_neg":"-5","neg_float":-0.5,"float":2.5,"exp":1e3,"str_exp":"1e3","true":true,"str":"insane",` +
+		`"max":9223372036854775807,"huge":99999999999999999999,"max_uint":18446744073709551615,"huge_float":1.5e300}`
+	root, err := DecodeString(json)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	tests := []struct {
+		path string
+
+		int      int
+		intOk    bool
+		int64    int64
+		int64Ok  bool
+		uint64   uint64
+		uint64Ok bool
+	}{
+		{path: "int", int: 5, intOk: true, int64: 5, int64Ok: true, uint64: 5, uint64Ok: true},
+		{path: "neg", int: -5, intOk: true, int64: -5, int64Ok: true},
+		{path: "neg_zero", intOk: true, int64Ok: true, uint64Ok: true},
+		{path: "str_neg", int: -5, intOk: true, int64: -5, int64Ok: true},
+		{path: "neg_float", int: -1, intOk: true, int64: -1, int64Ok: true},
+		{path: "float", int: 3, intOk: true, int64: 3, int64Ok: true, uint64: 3, uint64Ok: true},
+		{path: "exp", int: 1000, intOk: true},
+		{path: "str_exp", int: 1000, intOk: true},
+		{path: "true", int: 1, intOk: true, int64: 1, int64Ok: true, uint64: 1, uint64Ok: true},
+		{path: "max", int: 9223372036854775807, intOk: true, int64: 9223372036854775807, int64Ok: true, uint64: 9223372036854775807, uint64Ok: true},
+		{path: "max_uint", uint64: 18446744073709551615, uint64Ok: true},
+		{path: "huge"},
+		{path: "huge_float"},
+		{path: "str"},
+		{path: "missing"},
+	}
+
+	for _, test := range tests {
+		node := root.Dig(test.path)
+
+		i, ok := node.TryInt()
+		assert.Equal(t, test.int, i, "wrong int for %s", test.path)
+		assert.Equal(t, test.intOk, ok, "wrong int presence for %s", test.path)
+
+		i64, ok := node.TryInt64()
+		assert.Equal(t, test.int64, i64, "wrong int64 for %s", test.path)
+		assert.Equal(t, test.int64Ok, ok, "wrong int64 presence for %s", test.path)
+
+		u64, ok := node.TryUint64()
+		assert.Equal(t, test.uint64, u64, "wrong uint64 for %s", test.path)
+		assert.Equal(t, test.uint64Ok, ok, "wrong uint64 presence for %s", test.path)
+	}
+}
+
+func TestIsNumber(t *testing.T) {
+	valid := []string{"0", "-0", "5", "-5", "10", "0.5", "-0.5", "1e5", "1E+5", "1.5e-5"}
+	invalid := []string{"", "-", "01", ".5", "5.", "1e", "1e+", "+5", "0x5", "5 ", "--5", "abc"}
+
+	for _, s := range valid {
+		assert.True(t, isNumber(s), "%q should be a number", s)
+	}
+	for _, s := range invalid {
+		assert.False(t, isNumber(s), "%q shouldn't be a number", s)
+	}
+}
+
+func TestStrictNumbers(t *testing.T) {
+	json := `{"small":100,"big":300,"neg":-5,"neg_zero":-0,"max":9223372036854775807,"huge":9223372036854775808,"frac":1.5,"exp":1e3,"str":"5"}`
+	root, err := DecodeString(json)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	strict := func(path string) *StrictNode {
+		node, err := root.DigStrict(path)
+		assert.NoError(t, err, "node should be found")
+		return node
+	}
+
+	i8, err := strict("small").AsInt8()
+	assert.NoError(t, err, "number should fit")
+	assert.Equal(t, int8(100), i8, "wrong number")
+
+	_, err = strict("big").AsInt8()
+	assert.Equal(t, ErrNumberOverflow, err, "wrong error")
+
+	u8, err := strict("big").AsUint16()
+	assert.NoError(t, err, "number should fit")
+	assert.Equal(t, uint16(300), u8, "wrong number")
+
+	_, err = strict("neg").AsUint64()
+	assert.Equal(t, ErrNumberOverflow, err, "wrong error")
+
+	u64, err := strict("neg_zero").AsUint64()
+	assert.NoError(t, err, "number should fit")
+	assert.Equal(t, uint64(0), u64, "wrong number")
+
+	i64, err := strict("max").AsInt64()
+	assert.NoError(t, err, "number should fit")
+	assert.Equal(t, int64(9223372036854775807), i64, "wrong number")
+
+	_, err = strict("huge").AsInt64()
+	assert.Equal(t, ErrNumberOverflow, err, "wrong error")
+
+	u64, err = strict("huge").AsUint64()
+	assert.NoError(t, err, "number should fit")
+	assert.Equal(t, uint64(9223372036854775808), u64, "wrong number")
+
+	_, err = strict("frac").AsInt()
+	assert.Equal(t, ErrNotInteger, err, "wrong error")
+
+	_, err = strict("exp").AsInt32()
+	assert.Equal(t, ErrNotInteger, err, "wrong error")
+
+	_, err = strict("str").AsInt()
+	assert.Equal(t, ErrNotNumber, err, "wrong error")
+}
+
+func TestStrictFloatPrecision(t *testing.T) {
+	tests := []struct {
+		json string
+		err  error
+	}{
+		{json: `0`, err: nil},
+		{json: `-0.0`, err: nil},
+		{json: `0.1`, err: nil},
+		{json: `1.23`, err: nil},
+		{json: `100.500`, err: nil},
+		{json: `1e300`, err: nil},
+		{json: `12345.6789e-3`, err: nil},
+		{json: `9007199254740992`, err: nil},
+		{json: `9007199254740993`, err: ErrPrecisionLoss},
+		{json: `0.10000000000000000001`, err: ErrPrecisionLoss},
+		{json: `1e-400`, err: ErrPrecisionLoss},
+		{json: `1e400`, err: ErrNumberOverflow},
+	}
+
+	for _, test := range tests {
+		root, err := DecodeString(test.json)
+		assert.NoError(t, err, "error while decoding")
+
+		_, err = root.MutateToStrict().AsFloat()
+		assert.Equal(t, test.err, err, "wrong error for %s", test.json)
+		Release(root)
+	}
+}
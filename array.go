@@ -0,0 +1,173 @@
+package insaneJSON
+
+import "sort"
+
+// RemoveRange deletes array elements from i to j, j isn't included, order of other elements is kept
+func (n *Node) RemoveRange(i, j int) *Node {
+	n.debugCheck()
+
+	if j < i {
+		return nil
+	}
+
+	return n.Splice(i, j-i)
+}
+
+// Truncate deletes array elements starting from index l, so array length becomes at most l
+func (n *Node) Truncate(l int) *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitArray != hellBitArray || l < 0 {
+		return nil
+	}
+	if l >= len(n.nodes) {
+		return n
+	}
+
+	return n.Splice(l, len(n.nodes)-l)
+}
+
+/*
+Splice deletes del elements starting from index i and inserts provided nodes in their place.
+Inserted nodes should be detached, e.g. created with DeepCopy() or DecodeStringAdditional().
+Returns nil and does nothing if range is wrong, some node is attached, inserted twice or is one of the array parents.
+It doesn't allocate unless array grows over its capacity.
+*/
+func (n *Node) Splice(i, del int, insert ...*Node) *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitArray != hellBitArray {
+		return nil
+	}
+
+	l := len(n.nodes)
+	if i < 0 || del < 0 || i > l || del > l-i {
+		return nil
+	}
+
+	for k, node := range insert {
+		if node == nil || node.parent != nil || node.bits&(hellBitField|hellBitEscapedField|hellBitEnd|hellBitArrayEnd) != 0 {
+			return nil
+		}
+		for _, prev := range insert[:k] {
+			if prev == node {
+				return nil
+			}
+		}
+		for p := n; p != nil; p = p.parent {
+			if p == node {
+				return nil
+			}
+		}
+	}
+
+	if del == 0 && len(insert) == 0 {
+		return n
+	}
+
+	end := n.arrayEnd()
+	newLen := l - del + len(insert)
+	for len(n.nodes) < newLen {
+		n.nodes = append(n.nodes, nil)
+	}
+	copy(n.nodes[i+len(insert):], n.nodes[i+del:l])
+	for k := newLen; k < l; k++ {
+		n.nodes[k] = nil
+	}
+	n.nodes = n.nodes[:newLen]
+
+	for k, node := range insert {
+		node.parent = n
+		node.bits &= hellBitsDirtyReset
+		n.nodes[i+k] = node
+	}
+
+	if end == nil && newLen > 0 {
+		// restore lost end
+		end = n.getNode(nil)
+		end.bits = hellBitArrayEnd
+		end.next = n.next
+		end.parent = n
+	}
+
+	// deleted and shifted elements should find themselves again
+	n.bits += hellBitsDirtyStep
+	n.relinkElements(end)
+
+	return n
+}
+
+// Reverse reverses order of array elements
+func (n *Node) Reverse() *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitArray != hellBitArray {
+		return nil
+	}
+
+	if len(n.nodes) < 2 {
+		return n
+	}
+
+	end := n.arrayEnd()
+	reverseNodes(n.nodes)
+
+	n.bits += hellBitsDirtyStep
+	n.relinkElements(end)
+
+	return n
+}
+
+// SortFunc sorts array elements using less function, sort is stable and doesn't allocate
+func (n *Node) SortFunc(less func(a, b *Node) bool) *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitArray != hellBitArray {
+		return nil
+	}
+
+	if len(n.nodes) < 2 {
+		return n
+	}
+
+	end := n.arrayEnd()
+	stableSort(n.nodes, less)
+
+	n.bits += hellBitsDirtyStep
+	n.relinkElements(end)
+
+	return n
+}
+
+// arrayEnd returns end node of array, it's nil if array is empty
+func (n *Node) arrayEnd() *Node {
+	if len(n.nodes) == 0 {
+		return nil
+	}
+
+	return n.nodes[len(n.nodes)-1].next
+}
+
+// nodesSorter sorts nodes with sort.Stable(), it's taken from nodesSorterPool, so sorting doesn't allocate
+type nodesSorter struct {
+	nodes []*Node
+	less  func(a, b *Node) bool
+}
+
+func (s *nodesSorter) Len() int           { return len(s.nodes) }
+func (s *nodesSorter) Less(i, j int) bool { return s.less(s.nodes[i], s.nodes[j]) }
+func (s *nodesSorter) Swap(i, j int)      { s.nodes[i], s.nodes[j] = s.nodes[j], s.nodes[i] }
+
+func stableSort(nodes []*Node, less func(a, b *Node) bool) {
+	s := nodesSorterPool.Get().(*nodesSorter)
+	s.nodes, s.less = nodes, less
+	sort.Stable(s)
+	s.nodes, s.less = nil, nil
+	nodesSorterPool.Put(s)
+}
+
+func reverseNodes(nodes []*Node) {
+	for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	}
+}
@@ -0,0 +1,208 @@
+package insaneJSON
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveRange(t *testing.T) {
+	root, err := DecodeString(`{"a":[0,1,2,3,4,5],"b":"c"}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	a := root.Dig("a")
+	five := a.Dig("5")
+	a.RemoveRange(1, 3)
+	assert.Equal(t, `{"a":[0,3,4,5],"b":"c"}`, root.EncodeToString(), "wrong result json")
+	assert.Equal(t, 3, a.Dig("1").AsInt(), "wrong result")
+
+	five.Suicide()
+	assert.Equal(t, `{"a":[0,3,4],"b":"c"}`, root.EncodeToString(), "shifted element should find itself")
+
+	a.RemoveRange(2, 3)
+	assert.Equal(t, `{"a":[0,3],"b":"c"}`, root.EncodeToString(), "wrong result json")
+
+	a.RemoveRange(1, 1)
+	assert.Equal(t, `{"a":[0,3],"b":"c"}`, root.EncodeToString(), "empty range shouldn't change json")
+
+	assert.Nil(t, a.RemoveRange(1, 0), "wrong range should fail")
+	assert.Nil(t, a.RemoveRange(-1, 1), "wrong range should fail")
+	assert.Nil(t, a.RemoveRange(1, 3), "wrong range should fail")
+	assert.Nil(t, root.RemoveRange(0, 1), "object should fail")
+
+	a.RemoveRange(0, 2)
+	assert.Equal(t, `{"a":[],"b":"c"}`, root.EncodeToString(), "wrong result json")
+
+	a.AddElement().MutateToInt(1)
+	assert.Equal(t, `{"a":[1],"b":"c"}`, root.EncodeToString(), "wrong result json")
+}
+
+func TestTruncate(t *testing.T) {
+	root, err := DecodeString(`[0,1,2,3]`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.Truncate(10)
+	assert.Equal(t, `[0,1,2,3]`, root.EncodeToString(), "wrong result json")
+
+	root.Truncate(2)
+	assert.Equal(t, `[0,1]`, root.EncodeToString(), "wrong result json")
+
+	root.AddElement().MutateToInt(5)
+	assert.Equal(t, `[0,1,5]`, root.EncodeToString(), "wrong result json")
+
+	root.Truncate(0)
+	assert.Equal(t, `[]`, root.EncodeToString(), "wrong result json")
+	assert.Nil(t, root.Truncate(-1), "negative length should fail")
+}
+
+func TestSplice(t *testing.T) {
+	root, err := DecodeString(`{"a":[0,1,2,3],"b":"c"}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	a := root.Dig("a")
+	x, err := root.DecodeStringAdditional(`{"x":1}`)
+	assert.NoError(t, err, "error while decoding")
+	y, err := root.DecodeStringAdditional(`"y"`)
+	assert.NoError(t, err, "error while decoding")
+
+	a.Splice(1, 2, x, y, root.Dig("b").DeepCopy(root))
+	assert.Equal(t, `{"a":[0,{"x":1},"y","c",3],"b":"c"}`, root.EncodeToString(), "wrong result json")
+	assert.Equal(t, a, x.parent, "wrong parent")
+	assert.Equal(t, 1, a.Dig("1", "x").AsInt(), "wrong result")
+
+	y.Suicide()
+	assert.Equal(t, `{"a":[0,{"x":1},"c",3],"b":"c"}`, root.EncodeToString(), "inserted element should find itself")
+
+	z, err := root.DecodeStringAdditional(`null`)
+	assert.NoError(t, err, "error while decoding")
+	a.Splice(4, 0, z)
+	assert.Equal(t, `{"a":[0,{"x":1},"c",3,null],"b":"c"}`, root.EncodeToString(), "wrong result json")
+
+	assert.Nil(t, a.Splice(0, 0, x), "attached node should fail")
+	assert.Nil(t, a.Splice(0, 0, root.Node), "cycle should fail")
+	assert.Nil(t, a.Splice(0, 0, nil), "nil node should fail")
+	assert.Nil(t, a.Splice(6, 0), "wrong index should fail")
+	assert.Nil(t, a.Splice(4, 2), "wrong range should fail")
+
+	a.Splice(0, 5)
+	assert.Equal(t, `{"a":[],"b":"c"}`, root.EncodeToString(), "wrong result json")
+
+	w, err := root.DecodeStringAdditional(`[1]`)
+	assert.NoError(t, err, "error while decoding")
+	a.Splice(0, 0, w)
+	assert.Equal(t, `{"a":[[1]],"b":"c"}`, root.EncodeToString(), "lost end should be restored")
+}
+
+func TestSpliceDuplicate(t *testing.T) {
+	root, err := DecodeString(`[1,2]`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	x, err := root.DecodeStringAdditional(`3`)
+	assert.NoError(t, err, "error while decoding")
+
+	assert.Nil(t, root.Splice(1, 0, x, x), "duplicated node should fail")
+	assert.Equal(t, 2, len(root.AsArray()), "wrong length")
+	assert.Equal(t, `[1,2]`, root.EncodeToString(), "failed splice shouldn't change json")
+}
+
+func TestReverse(t *testing.T) {
+	root, err := DecodeString(`{"a":[1,2,3,4,5],"b":[],"c":[1]}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.Dig("a").Reverse()
+	root.Dig("b").Reverse()
+	root.Dig("c").Reverse()
+	assert.Equal(t, `{"a":[5,4,3,2,1],"b":[],"c":[1]}`, root.EncodeToString(), "wrong result json")
+
+	root.Dig("a", "0").Suicide()
+	assert.Equal(t, `{"a":[4,3,2,1],"b":[],"c":[1]}`, root.EncodeToString(), "wrong result json")
+	assert.Nil(t, root.Reverse(), "object should fail")
+}
+
+func TestSortFunc(t *testing.T) {
+	root, err := DecodeString(`[3,1,{"k":2,"v":"a"},0,{"k":2,"v":"b"},2]`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	key := func(node *Node) int {
+		if node.IsObject() {
+			return node.Dig("k").AsInt()
+		}
+		return node.AsInt()
+	}
+	root.SortFunc(func(a, b *Node) bool {
+		return key(a) < key(b)
+	})
+	assert.Equal(t, `[0,1,{"k":2,"v":"a"},{"k":2,"v":"b"},2,3]`, root.EncodeToString(), "sort should be stable")
+
+	root.Dig("3").Suicide()
+	assert.Equal(t, `[0,1,{"k":2,"v":"a"},2,3]`, root.EncodeToString(), "wrong result json")
+}
+
+func TestSortFuncBig(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, size := range []int{2, 19, 20, 21, 100, 1000} {
+		values := make([]string, size)
+		for i := range values {
+			values[i] = `[` + strconv.Itoa(r.Intn(size/2+1)) + `,` + strconv.Itoa(i) + `]`
+		}
+
+		root, err := DecodeString(`[` + strings.Join(values, ",") + `]`)
+		assert.NoError(t, err, "error while decoding")
+
+		root.SortFunc(func(a, b *Node) bool {
+			return a.Dig("0").AsInt() < b.Dig("0").AsInt()
+		})
+
+		sort.SliceStable(values, func(i, j int) bool {
+			a, _ := strconv.Atoi(values[i][1:strings.IndexByte(values[i], ',')])
+			b, _ := strconv.Atoi(values[j][1:strings.IndexByte(values[j], ',')])
+			return a < b
+		})
+		assert.Equal(t, `[`+strings.Join(values, ",")+`]`, root.EncodeToString(), "wrong result json for size %d", size)
+		Release(root)
+	}
+}
+
+func TestArrayNoAlloc(t *testing.T) {
+	if DebugMode {
+		t.Skip("debug mode doesn't reuse memory")
+	}
+
+	root, err := DecodeString(`[5,4,3,2,1,0,9,8,7,6]`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	less := func(a, b *Node) bool {
+		return a.AsInt() < b.AsInt()
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		root.Reverse()
+		root.SortFunc(less)
+		root.Splice(2, 3, root.Dig("2").DeepCopy(root), root.Dig("3").DeepCopy(root), root.Dig("4").DeepCopy(root))
+	})
+	assert.Equal(t, float64(0), allocs, "array operations shouldn't allocate")
+
+	root.RemoveRange(0, 5)
+	assert.Equal(t, `[5,6,7,8,9]`, root.EncodeToString(), "wrong result json")
+}
+
+func TestInsertElementIndexes(t *testing.T) {
+	root, err := DecodeString(`[1,2,3]`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	three := root.Dig("2")
+	root.InsertElement(0).MutateToInt(0)
+	three.Suicide()
+	assert.Equal(t, `[0,1,2]`, root.EncodeToString(), "shifted element should find itself")
+}
@@ -0,0 +1,107 @@
+package insaneJSON
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+/*
+AsBase64Bytes decodes base64 string and appends result to dst.
+Std and URL alphabets are detected automatically, padding is optional.
+String is decoded right from JSON data, it's unescaped only if it contains escape sequences like \/.
+*/
+func (n *Node) AsBase64Bytes(dst []byte) ([]byte, error) {
+	n.debugCheck()
+
+	if n == nil {
+		return dst, ErrNotString
+	}
+
+	var s string
+	switch n.bits & hellBitTypeFilter {
+	case hellBitString:
+		s = n.data
+	case hellBitEscapedString:
+		if strings.IndexByte(n.data, '\\') != -1 {
+			n.unescapeStr()
+			s = n.data
+		} else {
+			s = n.data[1 : len(n.data)-1]
+		}
+	default:
+		return dst, ErrNotString
+	}
+
+	enc := base64Encoding(s)
+	l := len(dst)
+	size := enc.DecodedLen(len(s))
+	if cap(dst)-l < size {
+		grown := make([]byte, l, l+size)
+		copy(grown, dst)
+		dst = grown
+	}
+
+	x, err := enc.Decode(dst[l:l+size], toByte(s))
+	if err != nil {
+		return dst[:l], err
+	}
+
+	return dst[:l+x], nil
+}
+
+// MutateToBase64 mutates to a string with value encoded using std alphabet with padding
+// encoded value is placed into root buffer like MutateToBytesCopy() does
+func (n *Node) MutateToBase64(root *Root, value []byte) *Node {
+	n.debugCheck()
+
+	return n.mutateToBase64(root, base64.StdEncoding, value)
+}
+
+// MutateToBase64URL mutates to a string with value encoded using URL alphabet without padding
+func (n *Node) MutateToBase64URL(root *Root, value []byte) *Node {
+	n.debugCheck()
+
+	return n.mutateToBase64(root, base64.RawURLEncoding, value)
+}
+
+func (n *Node) mutateToBase64(root *Root, enc *base64.Encoding, value []byte) *Node {
+	if n == nil || n.bits&hellBitField == hellBitField {
+		return nil
+	}
+
+	// base64 alphabets don't require escaping, so the string is placed with quotes
+	// as already escaped one and goes to the output as is
+	buf := root.decoder.buf
+	l := len(buf)
+	size := enc.EncodedLen(len(value))
+	if cap(buf)-l < size+2 {
+		grown := make([]byte, l, cap(buf)*2+size+2)
+		copy(grown, buf)
+		buf = grown
+	}
+	buf = buf[:l+size+2]
+	buf[l] = '"'
+	enc.Encode(buf[l+1:], value)
+	buf[l+size+1] = '"'
+	root.decoder.buf = buf
+
+	n.bits = hellBitEscapedString
+	n.data = toString(root.decoder.buf[l:])
+
+	return n
+}
+
+func base64Encoding(s string) *base64.Encoding {
+	padded := strings.HasSuffix(s, "=")
+	if strings.IndexAny(s, "-_") != -1 {
+		if padded {
+			return base64.URLEncoding
+		}
+		return base64.RawURLEncoding
+	}
+
+	if padded {
+		return base64.StdEncoding
+	}
+	return base64.RawStdEncoding
+}
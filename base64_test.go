@@ -0,0 +1,66 @@
+package insaneJSON
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsBase64Bytes(t *testing.T) {
+	value := []byte{0xfb, 0xff, 0xbf, 'i', 'n', 's', 'a', 'n', 'e'}
+	tests := []string{
+		`"+/+/aW5zYW5l"`,
+		`"+\/+\/aW5zYW5l"`,
+		`"-_-_aW5zYW5l"`,
+	}
+
+	dst := make([]byte, 0)
+	for _, json := range tests {
+		root, err := DecodeString(json)
+		assert.NoError(t, err, "error while decoding")
+
+		dst, err = root.AsBase64Bytes(dst[:0])
+		assert.NoError(t, err, "base64 should be decoded for %s", json)
+		assert.Equal(t, value, dst, "wrong value for %s", json)
+		Release(root)
+	}
+
+	root, err := DecodeString(`{"padded":"aW5zYW5l","raw":"aW5zYW5lIQ","url_padded":"_-_-aW5zYW5lIQ==","bad":"!!!","num":1}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	dst, err = root.Dig("padded").AsBase64Bytes([]byte("prefix:"))
+	assert.NoError(t, err, "base64 should be decoded")
+	assert.Equal(t, "prefix:insane", string(dst), "wrong value")
+
+	dst, err = root.Dig("raw").AsBase64Bytes(nil)
+	assert.NoError(t, err, "base64 should be decoded")
+	assert.Equal(t, "insane!", string(dst), "wrong value")
+
+	dst, err = root.Dig("url_padded").AsBase64Bytes(nil)
+	assert.NoError(t, err, "base64 should be decoded")
+	assert.Equal(t, []byte{0xff, 0xef, 0xfe, 'i', 'n', 's', 'a', 'n', 'e', '!'}, dst, "wrong value")
+
+	_, err = root.Dig("bad").AsBase64Bytes(nil)
+	assert.Error(t, err, "base64 shouldn't be decoded")
+
+	_, err = root.Dig("num").AsBase64Bytes(nil)
+	assert.Equal(t, ErrNotString, err, "wrong error")
+}
+
+func TestMutateToBase64(t *testing.T) {
+	root, err := DecodeString(`{"std":null,"url":null}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	value := []byte{0xfb, 0xff, 0xbf, 'i', 'n', 's', 'a', 'n', 'e', '!'}
+	root.Dig("std").MutateToBase64(root, value)
+	root.Dig("url").MutateToBase64URL(root, value)
+
+	assert.Equal(t, `{"std":"+/+/aW5zYW5lIQ==","url":"-_-_aW5zYW5lIQ"}`, root.EncodeToString(), "wrong result json")
+	assert.Equal(t, "+/+/aW5zYW5lIQ==", root.Dig("std").AsString(), "wrong string value")
+
+	dst, err := root.Dig("url").AsBase64Bytes(nil)
+	assert.NoError(t, err, "base64 should be decoded")
+	assert.Equal(t, value, dst, "wrong value")
+}
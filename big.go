@@ -0,0 +1,255 @@
+package insaneJSON
+
+import (
+	"encoding/json"
+	"math/big"
+)
+
+// AsNumber returns number literal as is, so it's never rounded
+// strings containing valid numbers are converted too, true and false are converted to "1" and "0"
+func (n *Node) AsNumber() json.Number {
+	n.debugCheck()
+
+	if !n.hasNumber() {
+		return ""
+	}
+
+	switch n.bits & hellBitTypeFilter {
+	case hellBitTrue:
+		return "1"
+	case hellBitFalse:
+		return "0"
+	default:
+		return json.Number(n.data)
+	}
+}
+
+func (n *StrictNode) AsNumber() (json.Number, error) {
+	n.debugCheck()
+
+	if n == nil || n.Node == nil || n.bits&hellBitNumber != hellBitNumber || !isNumber(n.data) {
+		return "", ErrNotNumber
+	}
+
+	return json.Number(n.data), nil
+}
+
+// AsBigInt returns nil if node can't be converted to a number or its exponent is above maxBigExponent,
+// fractions are rounded like AsInt() does
+func (n *Node) AsBigInt() *big.Int {
+	n.debugCheck()
+
+	number := n.AsNumber()
+	if number == "" {
+		return nil
+	}
+
+	x, scale, ok := parseBigNumber(string(number))
+	if !ok {
+		return nil
+	}
+	if scale >= 0 {
+		return x.Mul(x, pow10(scale))
+	}
+
+	// value is less than 0.1 if scale is bigger than count of digits
+	if -scale > len(number) {
+		return x.SetInt64(0)
+	}
+
+	return roundRat(new(big.Rat).SetFrac(x, pow10(-scale)))
+}
+
+// AsBigInt returns ErrNotInteger for numbers with fraction, numbers with exponent are allowed if they are integers
+// ErrNumberOverflow is returned if exponent is above maxBigExponent
+func (n *StrictNode) AsBigInt() (*big.Int, error) {
+	n.debugCheck()
+
+	number, err := n.AsNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	x, scale, ok := parseBigNumber(string(number))
+	if !ok {
+		return nil, ErrNumberOverflow
+	}
+	if scale >= 0 {
+		return x.Mul(x, pow10(scale)), nil
+	}
+
+	if x.Sign() == 0 {
+		return x, nil
+	}
+	if -scale > len(number) {
+		return nil, ErrNotInteger
+	}
+
+	q, r := new(big.Int).QuoRem(x, pow10(-scale), new(big.Int))
+	if r.Sign() != 0 {
+		return nil, ErrNotInteger
+	}
+
+	return q, nil
+}
+
+// AsBigFloat returns nil if node can't be converted to a number
+// precision of result is enough to keep all digits of the number literal
+func (n *Node) AsBigFloat() *big.Float {
+	n.debugCheck()
+
+	number := n.AsNumber()
+	if number == "" {
+		return nil
+	}
+
+	return parseBigFloat(string(number))
+}
+
+func (n *StrictNode) AsBigFloat() (*big.Float, error) {
+	n.debugCheck()
+
+	number, err := n.AsNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	x := parseBigFloat(string(number))
+	if x == nil {
+		return nil, ErrNumberOverflow
+	}
+
+	return x, nil
+}
+
+func (n *Node) MutateToBigInt(value *big.Int) *Node {
+	n.debugCheck()
+
+	if n == nil || value == nil || n.bits&hellBitField == hellBitField {
+		return n
+	}
+
+	n.bits = hellBitNumber
+	n.data = value.String()
+
+	return n
+}
+
+// MutateToBigFloat does nothing if value is infinite since JSON can't represent it
+func (n *Node) MutateToBigFloat(value *big.Float) *Node {
+	n.debugCheck()
+
+	if n == nil || value == nil || value.IsInf() || n.bits&hellBitField == hellBitField {
+		return n
+	}
+
+	n.bits = hellBitNumber
+	n.data = value.Text('g', -1)
+
+	return n
+}
+
+// MutateToNumberString uses value as number literal without any conversion, so exact values are kept
+// it does nothing if value isn't a valid JSON number
+func (n *Node) MutateToNumberString(value string) *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitField == hellBitField || !isNumber(value) {
+		return n
+	}
+
+	n.bits = hellBitNumber
+	n.data = value
+
+	return n
+}
+
+func parseBigFloat(s string) *big.Float {
+	// 4 bits per decimal digit is enough to keep all of them
+	prec := uint(len(s) * 4)
+	if prec < 64 {
+		prec = 64
+	}
+
+	x, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil
+	}
+
+	return x
+}
+
+// maxBigExponent limits numbers converted to big.Int, since 1e1000000 takes megabytes and a lot of time
+const maxBigExponent = 100000
+
+/*
+parseBigNumber splits valid number literal to integer of all its digits and decimal scale: 1.5e3 is 15 and 2,
+so big.Rat with its slow parsing of huge exponents isn't needed.
+Returns false if scale is above maxBigExponent.
+*/
+func parseBigNumber(s string) (*big.Int, int, bool) {
+	digits := make([]byte, 0, len(s))
+	i := 0
+	if s[i] == '-' {
+		digits = append(digits, '-')
+		i++
+	}
+
+	scale := 0
+	point := false
+	for ; i < len(s) && s[i] != 'e' && s[i] != 'E'; i++ {
+		if s[i] == '.' {
+			point = true
+			continue
+		}
+		digits = append(digits, s[i])
+		if point {
+			scale--
+		}
+	}
+
+	if i < len(s) {
+		i++
+		neg := false
+		if s[i] == '-' || s[i] == '+' {
+			neg = s[i] == '-'
+			i++
+		}
+
+		// saturated exponent is enough to detect overflow
+		exp := 0
+		for ; i < len(s) && exp <= maxBigExponent+len(s); i++ {
+			exp = exp*10 + int(s[i]-'0')
+		}
+		if neg {
+			exp = -exp
+		}
+		scale += exp
+	}
+
+	if scale > maxBigExponent {
+		return nil, 0, false
+	}
+
+	x, ok := new(big.Int).SetString(string(digits), 10)
+
+	return x, scale, ok
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// roundRat rounds half away from zero like math.Round() does
+func roundRat(r *big.Rat) *big.Int {
+	num := new(big.Int).Mul(r.Num(), big.NewInt(2))
+	if num.Sign() < 0 {
+		num.Sub(num, r.Denom())
+	} else {
+		num.Add(num, r.Denom())
+	}
+
+	den := new(big.Int).Mul(r.Denom(), big.NewInt(2))
+
+	return num.Quo(num, den)
+}
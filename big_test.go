@@ -0,0 +1,90 @@
+package insaneJSON
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBigNumbers(t *testing.T) {
+	root, err := DecodeString(`{"id":123456789012345678901234567890,"amount":0.1000000000000000000001,"half":-2.5,"exp":1.5e3,"str":"42","bad":"insane"}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	assert.Equal(t, json.Number("123456789012345678901234567890"), root.Dig("id").AsNumber(), "wrong number")
+	assert.Equal(t, json.Number("42"), root.Dig("str").AsNumber(), "wrong number")
+	assert.Equal(t, json.Number(""), root.Dig("bad").AsNumber(), "wrong number")
+
+	assert.Equal(t, "123456789012345678901234567890", root.Dig("id").AsBigInt().String(), "wrong big int")
+	assert.Equal(t, "-3", root.Dig("half").AsBigInt().String(), "wrong rounding")
+	assert.Equal(t, "1500", root.Dig("exp").AsBigInt().String(), "wrong big int")
+	assert.Nil(t, root.Dig("bad").AsBigInt(), "wrong big int")
+	assert.Nil(t, root.Dig("missing").AsBigFloat(), "wrong big float")
+
+	amount := root.Dig("amount").AsBigFloat()
+	assert.Equal(t, "0.1000000000000000000001", amount.Text('g', 22), "precision shouldn't be lost")
+
+	x, err := root.Dig("exp").MutateToStrict().AsBigInt()
+	assert.NoError(t, err, "integer with exponent is allowed")
+	assert.Equal(t, "1500", x.String(), "wrong big int")
+
+	_, err = root.Dig("half").MutateToStrict().AsBigInt()
+	assert.Equal(t, ErrNotInteger, err, "wrong error")
+
+	_, err = root.Dig("str").MutateToStrict().AsBigFloat()
+	assert.Equal(t, ErrNotNumber, err, "wrong error")
+}
+
+func TestBigIntExponent(t *testing.T) {
+	root, err := DecodeString(`[1.5e999999,1.5e3000000,1.5e100000,15e-1,-0.5,1e-3000000,-0e-99999999999999999999,12300e-2]`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	tests := []struct {
+		index  string
+		result string
+		strict string
+		err    error
+	}{
+		{index: "0", err: ErrNumberOverflow},
+		{index: "1", err: ErrNumberOverflow},
+		{index: "2", result: "15" + strings.Repeat("0", 99999), strict: "15" + strings.Repeat("0", 99999)},
+		{index: "3", result: "2", err: ErrNotInteger},
+		{index: "4", result: "-1", err: ErrNotInteger},
+		{index: "5", result: "0", err: ErrNotInteger},
+		{index: "6", result: "0", strict: "0"},
+		{index: "7", result: "123", strict: "123"},
+	}
+
+	for _, test := range tests {
+		x := root.Dig(test.index).AsBigInt()
+		if test.result == "" {
+			assert.Nil(t, x, "wrong big int for %s", test.index)
+		} else {
+			assert.Equal(t, test.result, x.String(), "wrong big int for %s", test.index)
+		}
+
+		x, err := root.Dig(test.index).MutateToStrict().AsBigInt()
+		assert.Equal(t, test.err, err, "wrong error for %s", test.index)
+		if test.err == nil {
+			assert.Equal(t, test.strict, x.String(), "wrong strict big int for %s", test.index)
+		}
+	}
+}
+
+func TestMutateToBigNumbers(t *testing.T) {
+	root, err := DecodeString(`{"a":"a","b":"b","c":"c","d":"d"}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	x, _ := new(big.Int).SetString("-98765432109876543210", 10)
+	root.Dig("a").MutateToBigInt(x)
+	root.Dig("b").MutateToBigFloat(big.NewFloat(1.5))
+	root.Dig("c").MutateToNumberString("1.00000000000000000000001e-5")
+	root.Dig("d").MutateToNumberString("not a number")
+
+	assert.Equal(t, `{"a":-98765432109876543210,"b":1.5,"c":1.00000000000000000000001e-5,"d":"d"}`, root.EncodeToString(), "wrong result json")
+}
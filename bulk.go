@@ -0,0 +1,270 @@
+package insaneJSON
+
+// AppendFields appends object fields to dst, so it's safe to add or delete fields while iterating over the result
+func (n *Node) AppendFields(dst []*Node) []*Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitObject != hellBitObject {
+		return dst
+	}
+
+	for _, field := range n.nodes {
+		if field.bits&hellBitEscapedField == hellBitEscapedField {
+			field.unescapeField()
+		}
+	}
+
+	return append(dst, n.nodes...)
+}
+
+// AppendElements appends array elements to dst, so it's safe to add or delete elements while iterating over the result
+func (n *Node) AppendElements(dst []*Node) []*Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitArray != hellBitArray {
+		return dst
+	}
+
+	return append(dst, n.nodes...)
+}
+
+// DeleteAll deletes all fields of object or all elements of array
+func (n *Node) DeleteAll() *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&(hellBitObject|hellBitArray) == 0 {
+		return n
+	}
+
+	if len(n.nodes) == 0 {
+		return n
+	}
+
+	// mark as dirty, so deleted nodes won't find themselves
+	n.bits += hellBitsDirtyStep
+	n.bits &= hellBitsMapsReset
+	n.nodes = n.nodes[:0]
+
+	return n
+}
+
+// DeleteElementsFunc deletes array elements for which fn returns true in one pass, order of other elements is kept
+func (n *Node) DeleteElementsFunc(fn func(index int, element *Node) bool) *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitArray != hellBitArray || len(n.nodes) == 0 {
+		return n
+	}
+
+	end := n.nodes[len(n.nodes)-1].next
+	l := 0
+	for i, element := range n.nodes {
+		if fn(i, element) {
+			continue
+		}
+		n.nodes[l] = element
+		l++
+	}
+
+	if l == len(n.nodes) {
+		return n
+	}
+
+	n.bits += hellBitsDirtyStep
+	n.nodes = n.nodes[:l]
+	n.relinkElements(end)
+
+	return n
+}
+
+// relinkElements restores linked list of array elements, end is the array end node
+func (n *Node) relinkElements(end *Node) {
+	l := len(n.nodes)
+	for i := 0; i < l-1; i++ {
+		n.nodes[i].next = n.nodes[i+1]
+	}
+	if l > 0 {
+		n.nodes[l-1].next = end
+	}
+}
+
+// DeleteFields deletes object fields with provided names in one pass, order of other fields is kept
+func (n *Node) DeleteFields(names ...string) *Node {
+	n.debugCheck()
+
+	if n.markFields(names) {
+		n.sweepFields(true, nil)
+	}
+
+	return n
+}
+
+// RetainFields deletes all object fields except ones with provided names in one pass, order of other fields is kept
+func (n *Node) RetainFields(names ...string) *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitObject != hellBitObject {
+		return n
+	}
+
+	n.markFields(names)
+	n.sweepFields(false, nil)
+
+	return n
+}
+
+// DeleteFieldsFunc deletes object fields for which fn returns true in one pass, order of other fields is kept
+// fn shouldn't add or delete fields of the object
+func (n *Node) DeleteFieldsFunc(fn func(name string, value *Node) bool) *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitObject != hellBitObject {
+		return n
+	}
+
+	for _, field := range n.nodes {
+		if field.bits&hellBitEscapedField == hellBitEscapedField {
+			field.unescapeField()
+		}
+	}
+	n.sweepFields(false, fn)
+
+	return n
+}
+
+// DeleteFieldsRecursive deletes fields with provided names from the object and all nested objects, including ones in arrays
+func (n *Node) DeleteFieldsRecursive(names ...string) *Node {
+	n.debugCheck()
+
+	// scalars taken from the pool may keep stale nodes of other containers
+	if n == nil || n.bits&(hellBitObject|hellBitArray) == 0 {
+		return n
+	}
+
+	n.DeleteFields(names...)
+	for _, node := range n.nodes {
+		if n.bits&hellBitObject == hellBitObject {
+			node = node.next
+		}
+		if node.bits&(hellBitObject|hellBitArray) != 0 {
+			node.DeleteFieldsRecursive(names...)
+		}
+	}
+
+	return n
+}
+
+// DeleteFieldsFuncRecursive deletes fields for which fn returns true from the object and all nested objects, including ones in arrays
+func (n *Node) DeleteFieldsFuncRecursive(fn func(name string, value *Node) bool) *Node {
+	n.debugCheck()
+
+	// scalars taken from the pool may keep stale nodes of other containers
+	if n == nil || n.bits&(hellBitObject|hellBitArray) == 0 {
+		return n
+	}
+
+	n.DeleteFieldsFunc(fn)
+	for _, node := range n.nodes {
+		if n.bits&hellBitObject == hellBitObject {
+			node = node.next
+		}
+		if node.bits&(hellBitObject|hellBitArray) != 0 {
+			node.DeleteFieldsFuncRecursive(fn)
+		}
+	}
+
+	return n
+}
+
+// markFields marks fields with provided names, fields map is used for big objects
+// returns false if nothing is marked
+func (n *Node) markFields(names []string) bool {
+	if n == nil || n.bits&hellBitObject != hellBitObject || len(n.nodes) == 0 || len(names) == 0 {
+		return false
+	}
+
+	marked := false
+	if len(n.nodes) > MapUseThreshold {
+		if n.bits&hellBitUseMap != hellBitUseMap {
+			n.buildFieldsMap()
+		}
+
+		for _, name := range names {
+			if index, has := n.maps.index[name]; has {
+				n.nodes[index].bits |= hellBitMark
+				marked = true
+			}
+		}
+
+		return marked
+	}
+
+	for _, field := range n.nodes {
+		if field.bits&hellBitEscapedField == hellBitEscapedField {
+			field.unescapeField()
+		}
+		for _, name := range names {
+			if field.data == name {
+				field.bits |= hellBitMark
+				marked = true
+				break
+			}
+		}
+	}
+
+	return marked
+}
+
+// sweepFields deletes fields using fn if it isn't nil, otherwise it deletes marked or unmarked fields
+// it also clears marks and updates fields map
+func (n *Node) sweepFields(deleteMarked bool, fn func(name string, value *Node) bool) {
+	if len(n.nodes) == 0 {
+		return
+	}
+
+	useMap := n.bits&hellBitUseMap == hellBitUseMap
+	end := n.nodes[len(n.nodes)-1].next.next
+	l := 0
+	for i, field := range n.nodes {
+		shouldDelete := false
+		if fn != nil {
+			shouldDelete = fn(field.data, field.next)
+		} else {
+			shouldDelete = (field.bits&hellBitMark == hellBitMark) == deleteMarked
+		}
+		field.bits &^= hellBitMark
+
+		if shouldDelete {
+			if useMap {
+				delete(n.maps.index, field.data)
+			}
+			continue
+		}
+
+		if useMap && i != l {
+			n.maps.index[field.data] = l
+		}
+		n.nodes[l] = field
+		l++
+	}
+
+	if l == len(n.nodes) {
+		return
+	}
+
+	n.bits += hellBitsDirtyStep
+	n.bits &^= hellBitUseFoldMap
+	n.nodes = n.nodes[:l]
+	n.relinkFields(end)
+}
+
+// relinkFields restores linked list of object fields, end is the object end node
+func (n *Node) relinkFields(end *Node) {
+	l := len(n.nodes)
+	for i := 0; i < l-1; i++ {
+		n.nodes[i].next.next = n.nodes[i+1]
+	}
+	if l > 0 {
+		n.nodes[l-1].next.next = end
+	}
+}
@@ -0,0 +1,186 @@
+package insaneJSON
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendFields(t *testing.T) {
+	jsons := []string{
+		`{"a":"a","b":"b","c":"c","d":"d"}`,
+		bigJSON,
+	}
+
+	fields := make([]*Node, 0)
+	for _, json := range jsons {
+		root, err := DecodeString(json)
+		assert.NoError(t, err, "error while decoding")
+
+		fields = root.AppendFields(fields[:0])
+		for _, field := range fields {
+			field.Suicide()
+		}
+		assert.Equal(t, `{}`, root.EncodeToString(), "all fields should be deleted")
+		Release(root)
+	}
+}
+
+func TestAppendElements(t *testing.T) {
+	root, err := DecodeString(`[0,1,{"a":[2]},[3],4]`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	for _, element := range root.AppendElements(nil) {
+		element.Suicide()
+	}
+	assert.Equal(t, `[]`, root.EncodeToString(), "all elements should be deleted")
+
+	root.AddElement().MutateToInt(5)
+	assert.Equal(t, `[5]`, root.EncodeToString(), "wrong result json")
+}
+
+func TestDeleteAll(t *testing.T) {
+	root, err := DecodeString(`{"obj":{"a":1,"b":[1,2]},"arr":[1,{"c":2},3],"x":"x"}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	obj := root.Dig("obj")
+	field := obj.Dig("a")
+	obj.DeleteAll()
+	root.Dig("arr").DeleteAll()
+	field.Suicide()
+
+	assert.Equal(t, `{"obj":{},"arr":[],"x":"x"}`, root.EncodeToString(), "wrong result json")
+	assert.Nil(t, obj.Dig("a"), "field should be deleted")
+
+	obj.AddField("new").MutateToInt(1)
+	assert.Equal(t, `{"obj":{"new":1},"arr":[],"x":"x"}`, root.EncodeToString(), "wrong result json")
+
+	fields := 30
+	for i := 0; i < fields; i++ {
+		root.AddField(strconv.Itoa(i))
+	}
+	assert.NotNil(t, root.Dig("29"), "field should be found")
+	root.DeleteAll()
+	assert.Nil(t, root.Dig("29"), "field shouldn't be found")
+	assert.Equal(t, `{}`, root.EncodeToString(), "wrong result json")
+}
+
+func TestDeleteElementsFunc(t *testing.T) {
+	tests := []struct {
+		json   string
+		result string
+	}{
+		{json: `[]`, result: `[]`},
+		{json: `[1,3]`, result: `[1,3]`},
+		{json: `[0,2]`, result: `[]`},
+		{json: `[0,1,2,3,4]`, result: `[1,3]`},
+		{json: `[1,{"a":0},[0,2],2,"3",{}]`, result: `[1,{"a":0},[0,2],"3",{}]`},
+		{json: `[[1],0,[2],4]`, result: `[[1],[2]]`},
+	}
+
+	for _, test := range tests {
+		root, err := DecodeString(`{"arr":` + test.json + `,"last":true}`)
+		assert.NoError(t, err, "error while decoding")
+
+		root.Dig("arr").DeleteElementsFunc(func(index int, element *Node) bool {
+			return element.IsNumber() && element.AsInt()%2 == 0
+		})
+		assert.Equal(t, `{"arr":`+test.result+`,"last":true}`, root.EncodeToString(), "wrong result json for %s", test.json)
+
+		root.Dig("arr").AddElement().MutateToInt(7)
+		assert.Equal(t, 7, root.Dig("arr", strconv.Itoa(len(root.Dig("arr").AsArray())-1)).AsInt(), "wrong element")
+		Release(root)
+	}
+}
+
+func TestDeleteFields(t *testing.T) {
+	tests := []struct {
+		json   string
+		names  []string
+		result string
+	}{
+		{json: `{}`, names: []string{"a"}, result: `{}`},
+		{json: `{"a":1}`, names: []string{"a"}, result: `{}`},
+		{json: `{"a":1,"b":2,"c":3}`, names: []string{"a", "c"}, result: `{"b":2}`},
+		{json: `{"a":{"x":1},"b":[2],"c":3,"d":{}}`, names: []string{"b", "d", "missing"}, result: `{"a":{"x":1},"c":3}`},
+		{json: `{"a\"":1,"b":2}`, names: []string{`a"`}, result: `{"b":2}`},
+		{json: bigJSON, names: []string{"compaqny", "_id1", "guid1"}, result: ``},
+	}
+
+	for _, test := range tests {
+		root, err := DecodeString(`{"obj":` + test.json + `,"last":true}`)
+		assert.NoError(t, err, "error while decoding")
+
+		obj := root.Dig("obj")
+		obj.DeleteFields(test.names...)
+		for _, name := range test.names {
+			assert.Nil(t, obj.Dig(name), "field %s should be deleted", name)
+		}
+		if test.result != "" {
+			assert.Equal(t, `{"obj":`+test.result+`,"last":true}`, root.EncodeToString(), "wrong result json")
+		}
+
+		obj.AddField("new").MutateToInt(1)
+		assert.Equal(t, 1, obj.Dig("new").AsInt(), "field should be added")
+		Release(root)
+	}
+
+	root, err := DecodeString(bigJSON)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	assert.NotNil(t, root.Dig("compaqny1"), "field should be found")
+	root.DeleteFields("_id", "compaqny1")
+	assert.Nil(t, root.Dig("compaqny1"), "field should be deleted")
+	assert.Equal(t, "ac784884-a6a3-4987-bb0f-d19e09462677", root.Dig("guid1").AsString(), "wrong field value")
+	assert.Equal(t, "PARCOE", root.Dig("compaaqny1").AsString(), "wrong field value")
+	assert.Equal(t, `{"index":0,"guid":`, root.EncodeToString()[:18], "order should be kept")
+}
+
+func TestRetainFields(t *testing.T) {
+	root, err := DecodeString(`{"a":1,"b":2,"c":3,"d":4}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.RetainFields("d", "b", "missing")
+	assert.Equal(t, `{"b":2,"d":4}`, root.EncodeToString(), "wrong result json")
+
+	root.RetainFields()
+	assert.Equal(t, `{}`, root.EncodeToString(), "wrong result json")
+}
+
+func TestDeleteFieldsRecursive(t *testing.T) {
+	root, err := DecodeString(`{"token":1,"user":{"name":"x","token":2,"sessions":[{"token":3,"id":1},{"id":2}]},"list":[[{"token":4}]]}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.DeleteFieldsRecursive("token")
+	assert.Equal(t, `{"user":{"name":"x","sessions":[{"id":1},{"id":2}]},"list":[[{}]]}`, root.EncodeToString(), "wrong result json")
+
+	root.DeleteFieldsFuncRecursive(func(name string, value *Node) bool {
+		return value.IsNumber()
+	})
+	assert.Equal(t, `{"user":{"name":"x","sessions":[{},{}]},"list":[[{}]]}`, root.EncodeToString(), "wrong result json")
+
+	root.Dig("user").DeleteFieldsFunc(func(name string, value *Node) bool {
+		return name == "name"
+	})
+	assert.Equal(t, `{"user":{"sessions":[{},{}]},"list":[[{}]]}`, root.EncodeToString(), "wrong result json")
+}
+
+func TestDeleteFieldsRecursiveScalar(t *testing.T) {
+	root, err := DecodeString(`[{"token":1,"x":{"token":2}}]`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	// scalar reuses pool node of the previous object
+	err = root.DecodeString(`["a",{"token":5}]`)
+	assert.NoError(t, err, "error while decoding")
+
+	root.Dig("0").DeleteFieldsRecursive("token")
+	root.Dig("0").DeleteFieldsFuncRecursive(func(name string, value *Node) bool { return true })
+	assert.Equal(t, `["a",{"token":5}]`, root.EncodeToString(), "scalar shouldn't delete anything")
+}
@@ -0,0 +1,431 @@
+/*
+Insane is a command-line JSON processor built on insane-json.
+
+Usage:
+
+	insane [flags] [path] [files...]
+
+It reads JSON or NDJSON from files or stdin, applies modifications and prints the value at the path.
+Path is either a dot separated list of fields and indexes: ".items.0.name",
+or a JSON Pointer: "/items/0/name". Empty path or "." selects the whole document.
+Path is required to read files, so it's an error to pass a file instead of it: use "insane . data.json".
+Input is NDJSON if its first line is a complete JSON, otherwise the whole input is a single document.
+Output is compact with one document per line unless --pretty is set, --compact just makes it explicit.
+
+Modifications are applied in order: --merge, --set, --delete. Then the document is checked by --validate
+and the value at the path is printed. Documents which don't match the schema are reported to stderr and skipped.
+*/
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	insaneJSON "github.com/ozontech/insane-json"
+	"github.com/ozontech/insane-json/schema"
+)
+
+const (
+	exitOK    = 0
+	exitFail  = 1
+	exitUsage = 2
+)
+
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+type assignment struct {
+	path []string
+	json string
+}
+
+type cli struct {
+	stdout *bufio.Writer
+	stderr io.Writer
+
+	pretty  bool
+	keys    bool
+	path    []string
+	deletes [][]string
+	sets    []assignment
+	merge   *insaneJSON.Root
+	schema  *schema.Schema
+
+	root     *insaneJSON.Root
+	scratch  *insaneJSON.Root
+	out      []byte
+	indented []byte
+	failed   bool
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("insane", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, "Usage: insane [flags] [path] [files...]")
+		flags.PrintDefaults()
+	}
+
+	var deletes, sets listFlag
+	pretty := flags.Bool("pretty", false, "indent output")
+	compact := flags.Bool("compact", false, "print every document in one line (default)")
+	keys := flags.Bool("keys", false, "print field names of an object or indexes of an array")
+	validate := flags.String("validate", "", "skip documents which don't match JSON Schema `file`")
+	merge := flags.String("merge", "", "merge fields of an object from `file` into every document")
+	flags.Var(&deletes, "delete", "delete node at `path`, can be repeated")
+	flags.Var(&sets, "set", "set node at path to JSON value: `path=json`, can be repeated")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if *pretty && *compact {
+		_, _ = fmt.Fprintln(stderr, "insane: --pretty and --compact can't be used together")
+		return exitUsage
+	}
+
+	c := &cli{
+		stdout:  bufio.NewWriter(stdout),
+		stderr:  stderr,
+		pretty:  *pretty,
+		keys:    *keys,
+		root:    insaneJSON.Spawn(),
+		scratch: insaneJSON.Spawn(),
+	}
+	defer insaneJSON.Release(c.root)
+	defer insaneJSON.Release(c.scratch)
+
+	if err := c.setup(deletes, sets, *validate, *merge); err != nil {
+		_, _ = fmt.Fprintf(stderr, "insane: %s\n", err.Error())
+		return exitUsage
+	}
+	if c.merge != nil {
+		defer insaneJSON.Release(c.merge)
+	}
+
+	files := flags.Args()
+	if len(files) > 0 {
+		if err := checkPath(files[0]); err != nil {
+			_, _ = fmt.Fprintf(stderr, "insane: %s\n", err.Error())
+			return exitUsage
+		}
+		c.path = parsePath(files[0])
+		files = files[1:]
+	}
+
+	if len(files) == 0 {
+		c.process("<stdin>", stdin)
+	}
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			c.fail("%s", err.Error())
+			continue
+		}
+		c.process(name, file)
+		_ = file.Close()
+	}
+
+	if err := c.stdout.Flush(); err != nil {
+		_, _ = fmt.Fprintf(stderr, "insane: %s\n", err.Error())
+		return exitFail
+	}
+
+	if c.failed {
+		return exitFail
+	}
+
+	return exitOK
+}
+
+func (c *cli) setup(deletes []string, sets []string, validate string, merge string) error {
+	for _, path := range deletes {
+		c.deletes = append(c.deletes, parsePath(path))
+	}
+
+	for _, set := range sets {
+		path, json, ok := strings.Cut(set, "=")
+		if !ok {
+			return fmt.Errorf("wrong --set %q: should be path=json", set)
+		}
+		// check JSON once, MutateToJSON() ignores errors
+		if err := c.scratch.DecodeString(json); err != nil {
+			return fmt.Errorf("wrong --set %q: value should be a valid JSON", set)
+		}
+		c.sets = append(c.sets, assignment{path: parsePath(path), json: json})
+	}
+
+	if validate != "" {
+		root, err := insaneJSON.DecodeFile(validate)
+		if err != nil {
+			return fmt.Errorf("can't read schema %s: %s", validate, err.Error())
+		}
+		c.schema, err = schema.Compile(root.Node)
+		insaneJSON.Release(root)
+		if err != nil {
+			return fmt.Errorf("can't compile schema %s: %s", validate, err.Error())
+		}
+	}
+
+	if merge != "" {
+		root, err := insaneJSON.DecodeFile(merge)
+		if err != nil {
+			return fmt.Errorf("can't read %s: %s", merge, err.Error())
+		}
+		if !root.IsObject() {
+			insaneJSON.Release(root)
+			return fmt.Errorf("can't merge %s: should be an object", merge)
+		}
+		c.merge = root
+	}
+
+	return nil
+}
+
+// process decodes NDJSON line by line or the whole input as a single document
+func (c *cli) process(name string, r io.Reader) {
+	reader := bufio.NewReaderSize(r, 64*1024)
+
+	line := 0
+	var first []byte
+	for {
+		data, err := reader.ReadBytes('\n')
+		line++
+		if len(bytes.TrimSpace(data)) != 0 {
+			first = data
+			break
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	if c.root.DecodeBytes(first) != nil {
+		rest, err := io.ReadAll(reader)
+		if err != nil {
+			c.fail("%s: %s", name, err.Error())
+			return
+		}
+
+		if err := c.root.DecodeBytes(append(first, rest...)); err != nil {
+			c.failDecode(name, err)
+			return
+		}
+		c.document(name, line)
+		return
+	}
+
+	c.document(name, line)
+	for {
+		data, err := reader.ReadBytes('\n')
+		line++
+		if len(bytes.TrimSpace(data)) != 0 {
+			if err := c.root.DecodeBytes(data); err != nil {
+				c.failDecode(fmt.Sprintf("%s:%d", name, line), err)
+			} else {
+				c.document(name, line)
+			}
+		}
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			c.fail("%s: %s", name, err.Error())
+			return
+		}
+	}
+}
+
+func (c *cli) document(name string, line int) {
+	root := c.root
+
+	if c.merge != nil && root.IsObject() {
+		root.MergeWith(c.merge.DeepCopy(root))
+	}
+
+	for _, set := range c.sets {
+		node := root.Upsert(root, set.path...)
+		if node == nil {
+			c.fail("%s:%d: can't set %s: path goes through a scalar or has too big index", name, line, strings.Join(set.path, "."))
+			continue
+		}
+		node.MutateToJSON(root, set.json)
+	}
+
+	for _, path := range c.deletes {
+		if len(path) == 0 {
+			root.MutateToNull()
+			continue
+		}
+		root.Dig(path...).Suicide()
+	}
+
+	if c.schema != nil {
+		err := c.schema.Validate(root.Node)
+		if err != nil {
+			for _, e := range err.(schema.Errors) {
+				c.fail("%s:%d: %s", name, line, e.Error())
+			}
+			return
+		}
+	}
+
+	node := root.Dig(c.path...)
+	if c.keys {
+		node = c.keysOf(node)
+		if node == nil {
+			c.fail("%s:%d: keys: value should be an object or an array", name, line)
+			return
+		}
+	}
+
+	c.write(node)
+}
+
+func (c *cli) keysOf(node *insaneJSON.Node) *insaneJSON.Node {
+	// decoding resets the scratch pool, so it doesn't grow with every document
+	_ = c.scratch.DecodeString("[]")
+	keys := c.scratch.Node
+	switch {
+	case node.IsObject():
+		for _, field := range node.AsFields() {
+			keys.AddElementNoAlloc(c.scratch).MutateToString(field.AsString())
+		}
+	case node.IsArray():
+		for i := range node.AsArray() {
+			keys.AddElementNoAlloc(c.scratch).MutateToInt(i)
+		}
+	default:
+		return nil
+	}
+
+	return keys
+}
+
+func (c *cli) write(node *insaneJSON.Node) {
+	c.out = c.out[:0]
+	if node == nil {
+		c.out = append(c.out, "null"...)
+	} else {
+		c.out = node.Encode(c.out)
+	}
+
+	if c.pretty {
+		c.indented = indent(c.indented[:0], c.out)
+		_, _ = c.stdout.Write(c.indented)
+	} else {
+		_, _ = c.stdout.Write(c.out)
+	}
+	_ = c.stdout.WriteByte('\n')
+}
+
+func (c *cli) fail(format string, args ...interface{}) {
+	c.failed = true
+	_, _ = fmt.Fprintf(c.stderr, "insane: "+format+"\n", args...)
+}
+
+// failDecode shifts the pointer line of beautiful errors, so it stays under the wrong character
+func (c *cli) failDecode(location string, err error) {
+	prefix := "insane: " + location + ": "
+	message := strings.ReplaceAll(err.Error(), "\n", "\n"+strings.Repeat(" ", len(prefix)))
+
+	c.failed = true
+	_, _ = fmt.Fprintln(c.stderr, prefix+message)
+}
+
+// checkPath catches files passed without a path, otherwise insane waits for stdin
+func checkPath(path string) error {
+	if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+		return fmt.Errorf("wrong path %q: it's a file, use \"insane . %s\" to read it", path, path)
+	}
+	if path != "" && path[0] != '.' && path[0] != '/' {
+		return fmt.Errorf("wrong path %q: should start with \".\" or \"/\"", path)
+	}
+
+	return nil
+}
+
+// parsePath converts dot separated path or JSON Pointer to the path for Dig()
+func parsePath(path string) []string {
+	if path == "" || path == "." {
+		return nil
+	}
+
+	if path[0] == '/' {
+		tokens := strings.Split(path[1:], "/")
+		for i, token := range tokens {
+			tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		}
+		return tokens
+	}
+
+	return strings.Split(strings.TrimPrefix(path, "."), ".")
+}
+
+// indent formats encoded JSON with two spaces, input is always valid since it's produced by Encode()
+func indent(dst []byte, src []byte) []byte {
+	depth := 0
+	newline := func() {
+		dst = append(dst, '\n')
+		for i := 0; i < depth; i++ {
+			dst = append(dst, ' ', ' ')
+		}
+	}
+
+	for i := 0; i < len(src); i++ {
+		b := src[i]
+		switch b {
+		case '"':
+			start := i
+			for i++; src[i] != '"'; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+			}
+			dst = append(dst, src[start:i+1]...)
+		case '{', '[':
+			if src[i+1] == '}' || src[i+1] == ']' {
+				dst = append(dst, b, src[i+1])
+				i++
+				continue
+			}
+			dst = append(dst, b)
+			depth++
+			newline()
+		case '}', ']':
+			depth--
+			newline()
+			dst = append(dst, b)
+		case ',':
+			dst = append(dst, b)
+			newline()
+		case ':':
+			dst = append(dst, ':', ' ')
+		default:
+			dst = append(dst, b)
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func runString(args []string, input string) (string, string, int) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	code := run(args, strings.NewReader(input), stdout, stderr)
+
+	return stdout.String(), stderr.String(), code
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	name = filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(name, []byte(content), 0o644)
+	assert.NoError(t, err, "error while writing file")
+
+	return name
+}
+
+func TestRun(t *testing.T) {
+	ndjson := "{\"a\":{\"b\":[1,2]},\"c/d\":\"x\"}\n\n{\"a\":{\"b\":[3]},\"c/d\":\"y\"}\n"
+
+	tests := []struct {
+		args     []string
+		input    string
+		expected string
+	}{
+		{args: nil, input: ndjson, expected: "{\"a\":{\"b\":[1,2]},\"c/d\":\"x\"}\n{\"a\":{\"b\":[3]},\"c/d\":\"y\"}\n"},
+		{args: []string{".a.b.0"}, input: ndjson, expected: "1\n3\n"},
+		{args: []string{"/c~1d"}, input: ndjson, expected: "\"x\"\n\"y\"\n"},
+		{args: []string{".a.x"}, input: ndjson, expected: "null\nnull\n"},
+		{args: []string{"--keys"}, input: ndjson, expected: "[\"a\",\"c/d\"]\n[\"a\",\"c/d\"]\n"},
+		{args: []string{"--keys", ".a.b"}, input: ndjson, expected: "[0,1]\n[0]\n"},
+		{args: []string{"--delete", "a", "--delete", "/c~1d"}, input: ndjson, expected: "{}\n{}\n"},
+		{args: []string{"--set", "a.b.2={\"e\":null}", ".a.b"}, input: ndjson, expected: "[1,2,{\"e\":null}]\n[3,null,{\"e\":null}]\n"},
+		{args: []string{"--compact"}, input: "{\n  \"a\": [1, {}],\n  \"b\": \"\\\"{,}\"\n}\n", expected: "{\"a\":[1,{}],\"b\":\"\\\"{,}\"}\n"},
+		{args: []string{"--pretty"}, input: "{\"a\":[1,{}],\"b\":\"\\\"{,}\",\"c\":{\"d\":[]}}", expected: "{\n  \"a\": [\n    1,\n    {}\n  ],\n  \"b\": \"\\\"{,}\",\n  \"c\": {\n    \"d\": []\n  }\n}\n"},
+		{args: nil, input: "\n  \n", expected: ""},
+	}
+
+	for _, test := range tests {
+		stdout, stderr, code := runString(test.args, test.input)
+		assert.Equal(t, test.expected, stdout, "wrong output for %v", test.args)
+		assert.Equal(t, "", stderr, "wrong errors for %v", test.args)
+		assert.Equal(t, exitOK, code, "wrong exit code for %v", test.args)
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	merge := writeFile(t, "merge.json", `{"env":"prod","a":0}`)
+	schema := writeFile(t, "schema.json", `{"properties":{"a":{"type":"integer"}}}`)
+	first := writeFile(t, "first.json", "{\"a\":1}\n{\"a\":\"x\"}\n{\"b\":2}\n")
+	second := writeFile(t, "second.json", `{"c":[]}`)
+
+	stdout, stderr, code := runString([]string{"--merge", merge, "--validate", schema, "--set", "a=2", ".", first, second}, "")
+	assert.Equal(t, "{\"a\":2,\"env\":\"prod\"}\n{\"a\":2,\"env\":\"prod\"}\n{\"b\":2,\"env\":\"prod\",\"a\":2}\n{\"c\":[],\"env\":\"prod\",\"a\":2}\n", stdout, "wrong output")
+	assert.Equal(t, "", stderr, "wrong errors")
+	assert.Equal(t, exitOK, code, "wrong exit code")
+
+	stdout, stderr, code = runString([]string{"--validate", schema, "", first, filepath.Join(t.TempDir(), "missing.json")}, "")
+	assert.Equal(t, "{\"a\":1}\n{\"b\":2}\n", stdout, "wrong output")
+	assert.True(t, strings.HasPrefix(stderr, "insane: "+first+":2: #/a: expected integer, got string\ninsane: open "), "wrong errors: %s", stderr)
+	assert.Equal(t, exitFail, code, "wrong exit code")
+
+	stdout, stderr, code = runString([]string{second}, "{}")
+	assert.Equal(t, "", stdout, "wrong output")
+	assert.Equal(t, "insane: wrong path \""+second+"\": it's a file, use \"insane . "+second+"\" to read it\n", stderr, "wrong errors")
+	assert.Equal(t, exitUsage, code, "wrong exit code")
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		args     []string
+		input    string
+		expected string
+		code     int
+	}{
+		{args: []string{"--pretty", "--compact"}, expected: "insane: --pretty and --compact can't be used together\n", code: exitUsage},
+		{args: []string{"a.b"}, input: "{}", expected: "insane: wrong path \"a.b\": should start with \".\" or \"/\"\n", code: exitUsage},
+		{args: []string{"--set", "a"}, expected: "insane: wrong --set \"a\": should be path=json\n", code: exitUsage},
+		{args: []string{"--set", "a=[1"}, expected: "insane: wrong --set \"a=[1\": value should be a valid JSON\n", code: exitUsage},
+		{args: nil, input: "{\"a\":1}\n{\"a\":\n", expected: "insane: <stdin>:2: expected value near `{\"a\":`\n                                            ^\n", code: exitFail},
+		{args: nil, input: "[\n1,", expected: "insane: <stdin>: unexpected ending of json near `[ 1`\n                                                    ^\n", code: exitFail},
+		{args: []string{"--keys"}, input: "1", expected: "insane: <stdin>:1: keys: value should be an object or an array\n", code: exitFail},
+		{args: []string{"--set", "a.b=1"}, input: "{\"a\":1}", expected: "insane: <stdin>:1: can't set a.b: path goes through a scalar or has too big index\n", code: exitFail},
+		{args: []string{"--set", "a.100000=1"}, input: "{}", expected: "insane: <stdin>:1: can't set a.100000: path goes through a scalar or has too big index\n", code: exitFail},
+	}
+
+	for _, test := range tests {
+		_, stderr, code := runString(test.args, test.input)
+		assert.Equal(t, test.expected, stderr, "wrong errors for %v", test.args)
+		assert.Equal(t, test.code, code, "wrong exit code for %v", test.args)
+	}
+}
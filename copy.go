@@ -0,0 +1,198 @@
+package insaneJSON
+
+import (
+	"strconv"
+	"strings"
+)
+
+/*
+DeepCopy clones node with all its children, so the result doesn't share anything with the source.
+Nodes are taken from dst pool and strings are copied into dst buffer,
+so the copy stays valid after the source root is released. dst can be nil, memory is allocated then.
+The copy is detached, use MutateToNode() to place it somewhere:
+dst.AddField("copy").MutateToNode(src.Dig("data").DeepCopy(dst))
+*/
+func (n *Node) DeepCopy(dst *Root) *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&(hellBitField|hellBitEscapedField) != 0 {
+		return nil
+	}
+
+	return n.deepCopy(dst, nil, true)
+}
+
+// deepCopy shares strings with the source if copyData is false, it's safe only within the same root
+func (n *Node) deepCopy(dst *Root, parent *Node, copyData bool) *Node {
+	c := n.getNode(dst)
+	c.bits = n.bits & (hellBitTypeFilter | hellBitEscapedField)
+	c.data = n.data
+	if copyData {
+		c.data = copyString(dst, n.data)
+	}
+	c.parent = parent
+	c.next = nil
+	c.nodes = c.nodes[:0]
+
+	var endBits hellBits
+	switch c.bits {
+	case hellBitObject:
+		endBits = hellBitEnd
+	case hellBitArray:
+		endBits = hellBitArrayEnd
+	default:
+		return c
+	}
+
+	if len(n.nodes) == 0 {
+		return c
+	}
+
+	var last *Node
+	for _, child := range n.nodes {
+		var value *Node
+		if endBits == hellBitEnd {
+			field := n.getNode(dst)
+			field.bits = child.bits & (hellBitTypeFilter | hellBitEscapedField)
+			field.data = child.data
+			if copyData {
+				field.data = copyString(dst, child.data)
+			}
+			field.parent = c
+			value = child.next.deepCopy(dst, c, copyData)
+			field.next = value
+			c.nodes = append(c.nodes, field)
+			if last != nil {
+				last.next = field
+			}
+		} else {
+			value = child.deepCopy(dst, c, copyData)
+			c.nodes = append(c.nodes, value)
+			if last != nil {
+				last.next = value
+			}
+		}
+		last = value
+	}
+
+	end := n.getNode(dst)
+	end.bits = endBits
+	end.data = ""
+	end.parent = c
+	end.next = nil
+	last.next = end
+
+	return c
+}
+
+/*
+MoveTo detaches node and attaches it to the parent, the node itself is kept, so references to it stay valid.
+For objects key is a field name, existing field is overwritten.
+For arrays key is an index to insert the node at, it's counted after the node is detached.
+Returns nil and does nothing if parent is the node itself or one of its children,
+if the node is the root, if key is wrong or if parent belongs to another root.
+Use DeepCopy() to move nodes between roots.
+*/
+func (n *Node) MoveTo(parent *Node, key string) *Node {
+	n.debugCheck()
+
+	if n == nil || parent == nil || n.parent == nil {
+		return nil
+	}
+	if n.bits&(hellBitField|hellBitEscapedField) != 0 {
+		n = n.next
+	}
+
+	parentTop := parent
+	for p := parent; p != nil; p = p.parent {
+		if p == n {
+			return nil
+		}
+		parentTop = p
+	}
+	// nodes of another root would point to its memory after Release()
+	top := n
+	for top.parent != nil {
+		top = top.parent
+	}
+	if top != parentTop {
+		return nil
+	}
+
+	var target *Node
+	switch parent.bits & hellBitTypeFilter {
+	case hellBitObject:
+		target = parent.Dig(key)
+		if target == n {
+			return n
+		}
+		n.Suicide()
+		if target == nil {
+			target = parent.AddField(key)
+		}
+	case hellBitArray:
+		pos, err := strconv.Atoi(key)
+		l := len(parent.nodes)
+		if n.parent == parent {
+			l--
+		}
+		if err != nil || pos < 0 || pos > l {
+			return nil
+		}
+		n.Suicide()
+		target = parent.InsertElement(pos)
+	default:
+		return nil
+	}
+
+	target.replaceWith(n)
+
+	return n
+}
+
+// replaceWith puts node in place of n in the parent of n, n becomes detached
+func (n *Node) replaceWith(node *Node) {
+	owner := n.parent
+	index := n.actualizeIndex()
+
+	node.next = n.next
+	node.parent = owner
+	node.setIndex(index)
+	node.bits = node.bits&hellBitsDirtyReset | (owner.bits & hellBitsDirtyFilter)
+
+	if owner.bits&hellBitObject == hellBitObject {
+		owner.nodes[index].next = node
+	} else {
+		owner.nodes[index] = node
+		if index > 0 {
+			owner.nodes[index-1].next = node
+		}
+	}
+
+	// keep end node pointing to the next node like decoder does
+	if l := len(node.nodes); l > 0 && node.bits&(hellBitObject|hellBitArray) != 0 {
+		last := node.nodes[l-1]
+		if node.bits&hellBitObject == hellBitObject {
+			last = last.next
+		}
+		last.next.next = node.next
+	}
+
+	n.parent = nil
+	n.next = nil
+}
+
+func copyString(dst *Root, s string) string {
+	if len(s) == 0 {
+		return ""
+	}
+
+	if dst == nil {
+		return strings.Clone(s)
+	}
+
+	l := len(dst.decoder.buf)
+	dst.decoder.buf = append(dst.decoder.buf, s...)
+
+	return toString(dst.decoder.buf[l:])
+}
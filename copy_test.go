@@ -0,0 +1,113 @@
+package insaneJSON
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeepCopy(t *testing.T) {
+	json := `{"a":{"b":[1,"x\n",{"c":null}],"e\"":{}},"f":[],"g":true}`
+	src, err := DecodeString(json)
+	assert.NoError(t, err, "error while decoding")
+
+	dst, err := DecodeString(`{}`)
+	defer Release(dst)
+	assert.NoError(t, err, "error while decoding")
+
+	dst.AddFieldNoAlloc(dst, "copy").MutateToNode(src.DeepCopy(dst))
+	dst.AddFieldNoAlloc(dst, "part").MutateToNode(src.Dig("a", "b").DeepCopy(dst))
+
+	src.Dig("a", "b", "1").MutateToString("changed")
+	src.Dig("a").AddField("new").MutateToInt(1)
+	Release(src)
+
+	// reuse released decoder to overwrite source memory
+	other, err := DecodeString(`{"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa":1}`)
+	defer Release(other)
+	assert.NoError(t, err, "error while decoding")
+
+	assert.Equal(t, `{"copy":`+json+`,"part":[1,"x\n",{"c":null}]}`, dst.EncodeToString(), "wrong result json")
+	assert.Equal(t, "x\n", dst.Dig("copy", "a", "b", "1").AsString(), "wrong result")
+	assert.Equal(t, 1, dst.Dig("part", "0").AsInt(), "wrong result")
+
+	dst.Dig("copy", "a", "b", "2").Suicide()
+	dst.Dig("copy", "a").AddField("h").MutateToString("i")
+	assert.Equal(t, `{"a":{"b":[1,"x\n"],"e\"":{},"h":"i"},"f":[],"g":true}`, dst.Dig("copy").EncodeToString(), "wrong result json")
+}
+
+func TestDeepCopyNoRoot(t *testing.T) {
+	root, err := DecodeString(`[{"a":"b"},2]`)
+	assert.NoError(t, err, "error while decoding")
+
+	c := root.DeepCopy(nil)
+	Release(root)
+
+	assert.Equal(t, `[{"a":"b"},2]`, c.EncodeToString(), "wrong result json")
+	assert.Nil(t, c.Dig("0").AsFields()[0].DeepCopy(nil), "field nodes shouldn't be copied")
+}
+
+func TestMoveTo(t *testing.T) {
+	root, err := DecodeString(`{"a":{"b":{"c":1}},"d":[1,2,3],"e":"f"}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	b := root.Dig("a", "b")
+	assert.Equal(t, b, b.MoveTo(root.Node, "x"), "node should be kept")
+	assert.Equal(t, `{"a":{},"d":[1,2,3],"e":"f","x":{"c":1}}`, root.EncodeToString(), "wrong result json")
+	assert.Equal(t, b, root.Dig("x"), "wrong result node")
+	assert.Equal(t, root.Node, b.parent, "wrong parent")
+
+	b.MoveTo(root.Dig("d"), "1")
+	assert.Equal(t, `{"a":{},"d":[1,{"c":1},2,3],"e":"f"}`, root.EncodeToString(), "wrong result json")
+	assert.Equal(t, b, root.Dig("d", "1"), "wrong result node")
+
+	root.Dig("d", "3").MoveTo(root.Dig("d"), "0")
+	assert.Equal(t, `{"a":{},"d":[3,1,{"c":1},2],"e":"f"}`, root.EncodeToString(), "wrong result json")
+
+	root.Dig("d", "0").MoveTo(root.Dig("d"), "3")
+	assert.Equal(t, `{"a":{},"d":[1,{"c":1},2,3],"e":"f"}`, root.EncodeToString(), "wrong result json")
+
+	root.Dig("e").MoveTo(root.Dig("a"), "e")
+	root.Dig("d", "2").MoveTo(root.Dig("a"), "e")
+	assert.Equal(t, `{"a":{"e":2},"d":[1,{"c":1},3]}`, root.EncodeToString(), "wrong result json")
+	assert.Equal(t, 2, root.Dig("a", "e").AsInt(), "wrong result")
+
+	assert.Nil(t, root.Dig("d").MoveTo(root.Dig("d", "1"), "x"), "cycle should be detected")
+	assert.Nil(t, root.Dig("d").MoveTo(root.Dig("d"), "0"), "cycle should be detected")
+	assert.Nil(t, root.MoveTo(root.Dig("a"), "x"), "root shouldn't be moved")
+	assert.Nil(t, root.Dig("a").MoveTo(root.Dig("d"), "10"), "wrong index should fail")
+	assert.Nil(t, root.Dig("a").MoveTo(root.Dig("d"), "x"), "wrong index should fail")
+	assert.Nil(t, root.Dig("a").MoveTo(root.Dig("d", "0"), "x"), "scalar parent should fail")
+	assert.Equal(t, `{"a":{"e":2},"d":[1,{"c":1},3]}`, root.EncodeToString(), "failed moves shouldn't change json")
+
+	root.Dig("d", "1").MoveTo(root.Node, "a")
+	assert.Equal(t, `{"a":{"c":1},"d":[1,3]}`, root.EncodeToString(), "wrong result json")
+	assert.Equal(t, 1, root.Dig("a", "c").AsInt(), "wrong result")
+	assert.Equal(t, 3, root.Dig("d", "1").AsInt(), "wrong result")
+}
+
+func TestMoveToAnotherRoot(t *testing.T) {
+	a, err := DecodeString(`{"x":[1,2]}`)
+	defer Release(a)
+	assert.NoError(t, err, "error while decoding")
+
+	b, err := DecodeString(`{"y":1}`)
+	defer Release(b)
+	assert.NoError(t, err, "error while decoding")
+
+	assert.Nil(t, a.Dig("x").MoveTo(b.Node, "moved"), "move to another root should fail")
+	assert.Nil(t, a.Dig("x", "0").MoveTo(b.Node, "moved"), "move to another root should fail")
+	assert.Equal(t, `{"x":[1,2]}`, a.EncodeToString(), "failed move shouldn't change json")
+	assert.Equal(t, `{"y":1}`, b.EncodeToString(), "failed move shouldn't change json")
+}
+
+func TestMoveToAncestorField(t *testing.T) {
+	root, err := DecodeString(`{"a":{"b":{"c":1}}}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.Dig("a", "b").MoveTo(root.Node, "a")
+	assert.Equal(t, `{"a":{"c":1}}`, root.EncodeToString(), "wrong result json")
+	assert.Equal(t, 1, root.Dig("a", "c").AsInt(), "wrong result")
+}
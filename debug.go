@@ -0,0 +1,90 @@
+//go:build insanedebug
+
+package insaneJSON
+
+import (
+	"sync/atomic"
+)
+
+/*
+Debug mode is enabled with insanedebug build tag: go test -tags insanedebug ./...
+Every decode of a root starts a new generation, nodes remember the generation they were created in,
+so access to a node after Release() of its root or after the root decoded another JSON panics.
+Released decoders are never reused and fresh node pools are used for every decode,
+so stale nodes can't point to the data of another JSON.
+It's slow and takes a lot of memory, so use it only for testing.
+*/
+const DebugMode = true
+
+var debugGenSeq uint64
+
+type nodeDebug struct {
+	decoder *decoder
+	gen     uint64
+}
+
+type decoderDebug struct {
+	gen      uint64
+	released bool
+}
+
+// debugReset starts a new generation and detaches nodes and buffer of the previous one
+func (d *decoder) debugReset() {
+	d.dbg.gen = atomic.AddUint64(&debugGenSeq, 1)
+	d.initPool()
+	d.buf = make([]byte, 0, cap(d.buf))
+}
+
+// debugStamp marks nodes taken from the pool with current generation
+func (d *decoder) debugStamp(from, to int) {
+	for _, node := range d.nodePool[from:to] {
+		node.dbg.decoder = d
+		node.dbg.gen = d.dbg.gen
+	}
+}
+
+func (d *decoder) debugStampNode(node *Node) {
+	node.dbg.decoder = d
+	node.dbg.gen = d.dbg.gen
+}
+
+func (d *decoder) debugRelease() {
+	if d.dbg.released {
+		panic("insaneJSON: root is released twice")
+	}
+	d.dbg.released = true
+}
+
+// debugRetire replaces released decoder in the pool, so it's never used again, it's called by backToPool() under decoderPoolMu
+func (d *decoder) debugRetire() {
+	fresh := &decoder{id: d.id, buf: make([]byte, 0, cap(d.buf))}
+	fresh.initPool()
+	fresh.updateStats()
+	decoderPool[d.id] = fresh
+}
+
+func (r *Root) debugCheck() {
+	if r != nil && r.decoder != nil && r.decoder.dbg.released {
+		panic("insaneJSON: root is used after Release()")
+	}
+}
+
+func (n *Node) debugCheck() {
+	if n == nil || n.dbg.decoder == nil {
+		return
+	}
+
+	d := n.dbg.decoder
+	if d.dbg.released {
+		panic("insaneJSON: node is used after Release() of its root")
+	}
+	if n.dbg.gen != d.dbg.gen {
+		panic("insaneJSON: node is used after its root decoded another JSON")
+	}
+}
+
+func (n *StrictNode) debugCheck() {
+	if n != nil {
+		n.Node.debugCheck()
+	}
+}
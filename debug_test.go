@@ -0,0 +1,92 @@
+//go:build insanedebug
+
+package insaneJSON
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugReleasedNode(t *testing.T) {
+	root, err := DecodeString(`{"a":{"b":"c"}}`)
+	assert.NoError(t, err, "error while decoding")
+
+	a := root.Dig("a")
+	added := root.AddFieldNoAlloc(root, "d")
+	Release(root)
+
+	// the decoder shouldn't be reused, so stale nodes keep pointing to the released data
+	other, err := DecodeString(`{"x":"y"}`)
+	defer Release(other)
+	assert.NoError(t, err, "error while decoding")
+
+	assert.PanicsWithValue(t, "insaneJSON: node is used after Release() of its root", func() { a.Dig("b") }, "stale access should panic")
+	assert.PanicsWithValue(t, "insaneJSON: node is used after Release() of its root", func() { added.MutateToInt(1) }, "stale access should panic")
+	assert.PanicsWithValue(t, "insaneJSON: node is used after Release() of its root", func() { a.MutateToStrict().AsString() }, "stale access should panic")
+	assert.NotPanics(t, func() { other.Dig("x") }, "new root should work")
+}
+
+func TestDebugDecodedAgain(t *testing.T) {
+	root, err := DecodeString(`{"a":"b"}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	a := root.Dig("a")
+	err = root.DecodeString(`{"a":"c"}`)
+	assert.NoError(t, err, "error while decoding")
+
+	assert.PanicsWithValue(t, "insaneJSON: node is used after its root decoded another JSON", func() { a.AsString() }, "stale access should panic")
+	assert.Equal(t, "c", root.Dig("a").AsString(), "wrong result")
+
+	additional, err := root.DecodeStringAdditional(`[1]`)
+	assert.NoError(t, err, "error while decoding")
+	assert.NotPanics(t, func() { root.Dig("a").MutateToNode(additional) }, "additional decode shouldn't invalidate nodes")
+	assert.Equal(t, `{"a":[1]}`, root.EncodeToString(), "wrong result json")
+}
+
+func TestDebugDoubleRelease(t *testing.T) {
+	root, err := DecodeString(`{}`)
+	assert.NoError(t, err, "error while decoding")
+
+	before := Stats()
+	Release(root)
+	assert.PanicsWithValue(t, "insaneJSON: root is released twice", func() { Release(root) }, "double release should panic")
+	assert.PanicsWithValue(t, "insaneJSON: root is used after Release()", func() { _ = root.DecodeString(`{}`) }, "released root shouldn't be reused")
+	assert.Equal(t, before.DecodersInUse-1, Stats().DecodersInUse, "pool shouldn't be broken")
+}
+
+func TestDebugAllocatedNode(t *testing.T) {
+	root, err := DecodeString(`{}`)
+	assert.NoError(t, err, "error while decoding")
+
+	node := root.AddField("a")
+	c := root.DeepCopy(nil)
+	Release(root)
+
+	assert.NotPanics(t, func() { node.MutateToInt(1) }, "allocated nodes aren't checked")
+	assert.NotPanics(t, func() { c.EncodeToString() }, "copies aren't checked")
+}
+
+func TestDebugConcurrentRelease(t *testing.T) {
+	before := Stats()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				root, err := DecodeString(`{"a":[1,2,3]}`)
+				if err != nil || root.Dig("a", "2").AsInt() != 3 {
+					t.Errorf("wrong decoding: %v", err)
+				}
+				Release(root)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, before.DecodersInUse, Stats().DecodersInUse, "all decoders should be back to the pool")
+}
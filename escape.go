@@ -0,0 +1,73 @@
+package insaneJSON
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+/*
+EncodeOptions changes string escaping of EncodeWithOptions() and EncodeToWithOptions().
+Zero value encodes exactly like Encode(): strings are escaped only if they contain quotes, backslashes or control characters,
+already escaped strings of decoded JSON are written as is.
+*/
+type EncodeOptions struct {
+	// DisableHTMLEscape keeps <, > and & of escaped strings as is instead of \u003c, \u003e and \u0026
+	DisableHTMLEscape bool
+	// ASCIIOnly escapes all non ASCII characters as \uXXXX using surrogate pairs if needed,
+	// invalid UTF-8 is always replaced with \ufffd in this mode
+	ASCIIOnly bool
+	// KeepInvalidUTF8 writes invalid UTF-8 bytes of escaped strings as is instead of \ufffd
+	KeepInvalidUTF8 bool
+}
+
+// appendField and appendEscapedString unescape into scratch, so encoding doesn't change nodes
+func (o *EncodeOptions) appendField(out []byte, n *Node, scratch *[]byte) []byte {
+	data := n.data
+	if n.bits&hellBitEscapedField == hellBitEscapedField {
+		if !o.shouldReescape(data) {
+			return append(out, data...)
+		}
+		i := strings.LastIndexByte(data, '"')
+		*scratch = appendUnescaped((*scratch)[:0], data[1:i])
+		data = toString(*scratch)
+	}
+
+	out = escapeString(out, data, o)
+	return append(out, ':')
+}
+
+func (o *EncodeOptions) appendEscapedString(out []byte, n *Node, scratch *[]byte) []byte {
+	if !o.shouldReescape(n.data) {
+		return append(out, n.data...)
+	}
+
+	*scratch = appendUnescaped((*scratch)[:0], n.data[1:len(n.data)-1])
+	return escapeString(out, toString(*scratch), o)
+}
+
+// shouldReescape checks if already escaped string has non ASCII characters which ASCIIOnly mode escapes
+func (o *EncodeOptions) shouldReescape(s string) bool {
+	if !o.ASCIIOnly {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return true
+		}
+	}
+
+	return false
+}
+
+func appendEscapedRune(out []byte, c rune) []byte {
+	if c < 0x10000 {
+		out = append(out, '\\', 'u')
+		return append(out, hex[c>>12&0xf], hex[c>>8&0xf], hex[c>>4&0xf], hex[c&0xf])
+	}
+
+	r1, r2 := utf16.EncodeRune(c)
+	out = appendEscapedRune(out, r1)
+	return appendEscapedRune(out, r2)
+}
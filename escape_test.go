@@ -0,0 +1,80 @@
+package insaneJSON
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeWithOptions(t *testing.T) {
+	tests := []struct {
+		json   string
+		opts   EncodeOptions
+		result string
+	}{
+		{json: `{"a<b":"<&>"}`, opts: EncodeOptions{}, result: `{"a<b":"<&>"}`},
+		{json: `{"a<b":"<&>\n"}`, opts: EncodeOptions{}, result: `{"a<b":"<&>\n"}`},
+		{json: `["при"]`, opts: EncodeOptions{ASCIIOnly: true}, result: `["\u043f\u0440\u0438"]`},
+		{json: `["😀"]`, opts: EncodeOptions{ASCIIOnly: true}, result: `["\ud83d\ude00"]`},
+		{json: "[\"a\u2028b\"]", opts: EncodeOptions{}, result: "[\"a\u2028b\"]"},
+		{json: "[\"a\u2028b\"]", opts: EncodeOptions{ASCIIOnly: true}, result: `["a\u2028b"]`},
+		{json: "[\"a\xffb\"]", opts: EncodeOptions{}, result: "[\"a\xffb\"]"},
+		{json: "[\"a\xffb\"]", opts: EncodeOptions{ASCIIOnly: true}, result: `["a\ufffdb"]`},
+		{json: `{"\"quoted\"" : "\"я\""}`, opts: EncodeOptions{ASCIIOnly: true}, result: `{"\"quoted\"" :"\"\u044f\""}`},
+		{json: `{"plain" : "plain"}`, opts: EncodeOptions{ASCIIOnly: true}, result: `{"plain" :"plain"}`},
+	}
+
+	for _, test := range tests {
+		root, err := DecodeString(test.json)
+		assert.NoError(t, err, "error while decoding")
+
+		assert.Equal(t, test.result, string(root.EncodeWithOptions(nil, test.opts)), "wrong encoding of %s", test.json)
+		Release(root)
+	}
+
+	root, err := DecodeString(`{}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.AddField("\u043c\u0438\u0440").MutateToString("<\u043c\u0438\u0440>")
+	result := `{"\u043c\u0438\u0440":"\u003c\u043c\u0438\u0440\u003e"}`
+	assert.Equal(t, result, string(root.EncodeWithOptions(nil, EncodeOptions{ASCIIOnly: true})), "wrong encoding")
+}
+
+func TestEncodeWithZeroOptions(t *testing.T) {
+	root, err := DecodeString(`{"a":"<\u0026>","b":1}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.AddField("c<\"").MutateToString("\"<\xff>\u2028\t")
+	root.AddField("d").MutateToString("<\xff>")
+	result := "{\"a\":\"<\\u0026>\",\"b\":1,\"c\\u003c\\\"\":\"\\\"\\u003c\\ufffd\\u003e\\u2028\\t\",\"d\":\"<\xff>\"}"
+	assert.Equal(t, result, root.EncodeToString(), "wrong encoding")
+	assert.Equal(t, result, string(root.EncodeWithOptions(nil, EncodeOptions{})), "zero options should encode like Encode()")
+
+	opts := EncodeOptions{DisableHTMLEscape: true, KeepInvalidUTF8: true}
+	result = "{\"a\":\"<\\u0026>\",\"b\":1,\"c<\\\"\":\"\\\"<\xff>\\u2028\\t\",\"d\":\"<\xff>\"}"
+	assert.Equal(t, result, string(root.EncodeWithOptions(nil, opts)), "wrong encoding")
+}
+
+func TestEncodeWithOptionsReadOnly(t *testing.T) {
+	json := `{"\"я\"":"<\"я\">"}`
+	root, err := DecodeString(json)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	opts := EncodeOptions{ASCIIOnly: true}
+	result := `{"\"\u044f\"":"\u003c\"\u044f\"\u003e"}`
+	assert.Equal(t, result, string(root.EncodeWithOptions(nil, opts)), "wrong encoding")
+
+	field := root.nodes[0]
+	assert.Equal(t, hellBitEscapedField, field.bits&hellBitEscapedField, "field shouldn't be unescaped")
+	assert.Equal(t, hellBitEscapedString, field.next.bits&hellBitTypeFilter, "value shouldn't be unescaped")
+	assert.Equal(t, json, root.EncodeToString(), "encoding shouldn't change nodes")
+
+	out := &bytes.Buffer{}
+	err = root.EncodeToWithOptions(out, opts)
+	assert.NoError(t, err, "error while encoding")
+	assert.Equal(t, result, out.String(), "wrong encoding")
+}
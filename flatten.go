@@ -0,0 +1,251 @@
+package insaneJSON
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FlattenOptions configures Flatten() and Unflatten()
+type FlattenOptions struct {
+	// Separator joins names of nested fields, it's required
+	Separator string
+	// KeepArrays keeps arrays as values, otherwise array elements become fields with index names
+	// for Unflatten() it means that numeric names create object fields instead of array elements
+	KeepArrays bool
+	// MaxDepth limits nesting of flattened names, deeper nodes are kept as values, 0 means no limit
+	// for Unflatten() the rest of the name becomes a field name at MaxDepth level
+	MaxDepth int
+}
+
+/*
+Flatten returns new object with fields of nested objects and arrays joined by separator:
+{"a":{"b":[1]}} becomes {"a.b.0":1}. The node itself isn't changed, use MutateToNode() to replace it.
+Separators and backslashes inside of names are escaped with a backslash.
+Empty objects and arrays are kept as values. Nodes and names are placed into the pool and buffer of the root,
+values share data with the node, so it should belong to the same root.
+Returns nil if the node isn't an object or an array.
+*/
+func (n *Node) Flatten(root *Root, sep string) *Node {
+	n.debugCheck()
+
+	return n.FlattenWithOptions(root, &FlattenOptions{Separator: sep})
+}
+
+func (n *Node) FlattenWithOptions(root *Root, opts *FlattenOptions) *Node {
+	n.debugCheck()
+
+	if n == nil || root == nil || opts == nil || opts.Separator == "" || n.bits&(hellBitObject|hellBitArray) == 0 {
+		return nil
+	}
+
+	result := n.getNode(root)
+	result.bits = hellBitObject
+	result.data = ""
+	result.parent = nil
+	result.next = nil
+	result.nodes = result.nodes[:0]
+
+	opts.flatten(root, result, n, "", 0)
+
+	return result
+}
+
+/*
+Unflatten is the reverse of Flatten(), it returns new object with nested objects and arrays
+created from names split by separator: {"a.b.0":1} becomes {"a":{"b":[1]}}.
+Array gaps are filled with nulls, if names conflict the last one wins.
+Indexes which need more than MaxArrayPadding nulls are used as object field names.
+Returns nil if the node isn't an object.
+*/
+func (n *Node) Unflatten(root *Root, sep string) *Node {
+	n.debugCheck()
+
+	return n.UnflattenWithOptions(root, &FlattenOptions{Separator: sep})
+}
+
+func (n *Node) UnflattenWithOptions(root *Root, opts *FlattenOptions) *Node {
+	n.debugCheck()
+
+	if n == nil || root == nil || opts == nil || opts.Separator == "" || n.bits&hellBitObject != hellBitObject {
+		return nil
+	}
+
+	result := n.getNode(root)
+	result.bits = hellBitObject
+	result.data = ""
+	result.parent = nil
+	result.next = nil
+	result.nodes = result.nodes[:0]
+
+	for _, field := range n.nodes {
+		if field.bits&hellBitEscapedField == hellBitEscapedField {
+			field.unescapeField()
+		}
+
+		node := result
+		name := field.data
+		for depth := 1; ; depth++ {
+			segment, rest, ok := "", "", false
+			if opts.MaxDepth != 0 && depth == opts.MaxDepth {
+				segment = opts.unescapeName(root, name)
+			} else {
+				segment, rest, ok = opts.cutName(root, name)
+			}
+
+			node = opts.child(root, node, segment)
+			if !ok {
+				break
+			}
+			name = rest
+		}
+
+		node.MutateToNode(field.next.deepCopy(root, nil, false))
+	}
+
+	return result
+}
+
+func (o *FlattenOptions) flatten(root *Root, dst *Node, node *Node, prefix string, depth int) {
+	isObject := node.bits&hellBitObject == hellBitObject
+	for i, child := range node.nodes {
+		name := ""
+		value := child
+		if isObject {
+			if child.bits&hellBitEscapedField == hellBitEscapedField {
+				child.unescapeField()
+			}
+			name = child.data
+			value = child.next
+		} else {
+			name = walkIndex(i)
+		}
+
+		key := o.appendName(root, prefix, name)
+
+		isNested := value.bits&hellBitObject == hellBitObject || (value.bits&hellBitArray == hellBitArray && !o.KeepArrays)
+		if isNested && len(value.nodes) > 0 && (o.MaxDepth == 0 || depth+1 < o.MaxDepth) {
+			o.flatten(root, dst, value, key, depth+1)
+			continue
+		}
+
+		dst.AddFieldNoAlloc(root, key).MutateToNode(value.deepCopy(root, nil, false))
+	}
+}
+
+// appendName places prefix and escaped name into the root buffer
+func (o *FlattenOptions) appendName(root *Root, prefix string, name string) string {
+	buf := root.decoder.buf
+	l := len(buf)
+	if prefix != "" {
+		buf = append(buf, prefix...)
+		buf = append(buf, o.Separator...)
+	}
+
+	sep := o.Separator
+	for i := 0; i < len(name); {
+		if name[i] == '\\' {
+			buf = append(buf, '\\', '\\')
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(name[i:], sep) {
+			// every byte is escaped, so the rest of the name can't form a separator with it
+			for j := 0; j < len(sep); j++ {
+				buf = append(buf, '\\', sep[j])
+			}
+			i += len(sep)
+			continue
+		}
+
+		buf = append(buf, name[i])
+		i++
+	}
+	root.decoder.buf = buf
+
+	return toString(buf[l:])
+}
+
+// cutName returns unescaped first segment of the name and the rest of the name after separator
+func (o *FlattenOptions) cutName(root *Root, name string) (string, string, bool) {
+	sep := o.Separator
+	escaped := false
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\\' && i+1 < len(name) {
+			escaped = true
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(name[i:], sep) {
+			segment := name[:i]
+			if escaped {
+				segment = o.unescapeName(root, segment)
+			}
+			return segment, name[i+len(sep):], true
+		}
+	}
+
+	if escaped {
+		return o.unescapeName(root, name), "", false
+	}
+
+	return name, "", false
+}
+
+func (o *FlattenOptions) unescapeName(root *Root, name string) string {
+	if strings.IndexByte(name, '\\') == -1 {
+		return name
+	}
+
+	buf := root.decoder.buf
+	l := len(buf)
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\\' && i+1 < len(name) {
+			i++
+		}
+		buf = append(buf, name[i])
+	}
+	root.decoder.buf = buf
+
+	return toString(buf[l:])
+}
+
+// child returns child node with provided name, node is converted to an object or an array if it's needed
+func (o *FlattenOptions) child(root *Root, node *Node, name string) *Node {
+	index := -1
+	if !o.KeepArrays {
+		if x, err := strconv.Atoi(name); err == nil && x >= 0 && name == walkIndex(x) {
+			index = x
+		}
+	}
+	// huge index would allocate huge array, so it becomes a field name
+	if index != -1 {
+		padding := index
+		if node.bits&hellBitArray == hellBitArray {
+			padding -= len(node.nodes)
+		}
+		if padding > MaxArrayPadding {
+			index = -1
+		}
+	}
+
+	switch {
+	case node.bits&hellBitObject == hellBitObject:
+	case node.bits&hellBitArray == hellBitArray && index != -1:
+	case index != -1:
+		node.MutateToArray()
+	default:
+		node.MutateToObject()
+	}
+
+	if node.bits&hellBitObject == hellBitObject {
+		return node.AddFieldNoAlloc(root, name)
+	}
+
+	for len(node.nodes) <= index {
+		node.AddElementNoAlloc(root)
+	}
+
+	return node.nodes[index]
+}
@@ -0,0 +1,86 @@
+package insaneJSON
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatten(t *testing.T) {
+	root, err := DecodeString(`{"a":{"b":[1,{"c":"d"}],"e":{}},"f":[],"g\"":null,"h":{"i":{"j":true}}}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	flat := root.Flatten(root, ".")
+	assert.Equal(t, `{"a.b.0":1,"a.b.1.c":"d","a.e":{},"f":[],"g\"":null,"h.i.j":true}`, flat.EncodeToString(), "wrong result json")
+	assert.Equal(t, `{"a":{"b":[1,{"c":"d"}],"e":{}},"f":[],"g\"":null,"h":{"i":{"j":true}}}`, root.EncodeToString(), "source shouldn't be changed")
+
+	flat = root.FlattenWithOptions(root, &FlattenOptions{Separator: "/", KeepArrays: true})
+	assert.Equal(t, `{"a/b":[1,{"c":"d"}],"a/e":{},"f":[],"g\"":null,"h/i/j":true}`, flat.EncodeToString(), "wrong result json")
+
+	flat = root.FlattenWithOptions(root, &FlattenOptions{Separator: ".", MaxDepth: 2})
+	assert.Equal(t, `{"a.b":[1,{"c":"d"}],"a.e":{},"f":[],"g\"":null,"h.i":{"j":true}}`, flat.EncodeToString(), "wrong result json")
+
+	root.MutateToNode(root.Dig("a").Flatten(root, "_"))
+	assert.Equal(t, `{"b_0":1,"b_1_c":"d","e":{}}`, root.EncodeToString(), "wrong result json")
+	assert.Equal(t, "d", root.Dig("b_1_c").AsString(), "wrong result")
+
+	assert.Nil(t, root.Dig("b_0").Flatten(root, "."), "scalar shouldn't be flattened")
+	assert.Nil(t, root.Flatten(root, ""), "separator is required")
+	assert.Nil(t, root.Flatten(nil, "."), "root is required")
+}
+
+func TestFlattenEscaping(t *testing.T) {
+	root, err := DecodeString(`{"a.b":{"c\\d":1,"e..f":{"::":2}},"x":{"y::z":[3]}}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	flat := root.Flatten(root, ".")
+	assert.Equal(t, `{"a\\.b.c\\\\d":1,"a\\.b.e\\.\\.f.::":2,"x.y::z.0":3}`, flat.EncodeToString(), "wrong result json")
+	assert.Equal(t, root.EncodeToString(), flat.Unflatten(root, ".").EncodeToString(), "unflatten should restore json")
+
+	flat = root.Flatten(root, "::")
+	assert.Equal(t, `{"a.b::c\\\\d":1,"a.b::e..f::\\:\\:":2,"x::y\\:\\:z::0":3}`, flat.EncodeToString(), "wrong result json")
+	assert.Equal(t, root.EncodeToString(), flat.Unflatten(root, "::").EncodeToString(), "unflatten should restore json")
+}
+
+func TestUnflatten(t *testing.T) {
+	root, err := DecodeString(`{"a.b.1":1,"a.b.0.c":"d","a.e":{},"f":[],"g.01":2,"h.i.j":true}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	result := root.Unflatten(root, ".")
+	assert.Equal(t, `{"a":{"b":[{"c":"d"},1],"e":{}},"f":[],"g":{"01":2},"h":{"i":{"j":true}}}`, result.EncodeToString(), "wrong result json")
+
+	result = root.UnflattenWithOptions(root, &FlattenOptions{Separator: ".", KeepArrays: true})
+	assert.Equal(t, `{"a":{"b":{"1":1,"0":{"c":"d"}},"e":{}},"f":[],"g":{"01":2},"h":{"i":{"j":true}}}`, result.EncodeToString(), "wrong result json")
+
+	result = root.UnflattenWithOptions(root, &FlattenOptions{Separator: ".", MaxDepth: 2})
+	assert.Equal(t, `{"a":{"b.1":1,"b.0.c":"d","e":{}},"f":[],"g":{"01":2},"h":{"i.j":true}}`, result.EncodeToString(), "wrong result json")
+
+	root.MutateToNode(root.Unflatten(root, "."))
+	assert.Equal(t, "d", root.Dig("a", "b", "0", "c").AsString(), "wrong result")
+
+	assert.Nil(t, root.Dig("a", "b").Unflatten(root, "."), "array shouldn't be unflattened")
+}
+
+func TestUnflattenConflicts(t *testing.T) {
+	root, err := DecodeString(`{"a":1,"a.b":2,"c.d":3,"c":4,"e.0":5,"e.x":6,"f.2":7}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	result := root.Unflatten(root, ".")
+	assert.Equal(t, `{"a":{"b":2},"c":4,"e":{"x":6},"f":[null,null,7]}`, result.EncodeToString(), "last name should win")
+}
+
+func TestUnflattenHugeIndex(t *testing.T) {
+	root, err := DecodeString(`{"a.30000000":1,"b.0":2,"b.1026":3,"c.1024":4}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	result := root.Unflatten(root, ".")
+	assert.Equal(t, `{"30000000":1}`, result.Dig("a").EncodeToString(), "huge index should be a field")
+	assert.Equal(t, `{"1026":3}`, result.Dig("b").EncodeToString(), "huge padding should turn array into object")
+	assert.Equal(t, 1025, len(result.Dig("c").AsArray()), "padding up to the limit should be added")
+	assert.True(t, len(root.decoder.nodePool) < 100000, "pool shouldn't grow")
+}
@@ -0,0 +1,172 @@
+package insaneJSON
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+DigFold is the same as Dig(), but object field names are compared with Unicode case folding.
+If many fields match, the first one is returned.
+Objects with more than MapUseThreshold fields use map of folded names, so lookups stay fast.
+*/
+func (n *Node) DigFold(path ...string) *Node {
+	n.debugCheck()
+
+	return n.digFold(nil, path)
+}
+
+/*
+DigFoldWith is the same as DigFold(), but field names are compared after normalize,
+e.g. to find "userId", "UserID" and "user_id" fields with the same name.
+It should fold case by itself if it's needed, use FoldFieldName() for that.
+Normalized names aren't indexed, so normalize is called for every field of objects on the path.
+*/
+func (n *Node) DigFoldWith(normalize func(name string) string, path ...string) *Node {
+	n.debugCheck()
+
+	return n.digFold(normalize, path)
+}
+
+func (n *Node) digFold(normalize func(name string) string, path []string) *Node {
+	node := n
+	for _, name := range path {
+		if node == nil {
+			return nil
+		}
+
+		switch node.bits & hellBitTypeFilter {
+		case hellBitObject:
+			node = node.digFoldField(normalize, name)
+		case hellBitArray:
+			index, err := strconv.Atoi(name)
+			if err != nil || index < 0 || index >= len(node.nodes) {
+				return nil
+			}
+			node = node.nodes[index]
+		default:
+			return nil
+		}
+	}
+
+	return node
+}
+
+func (n *Node) digFoldField(normalize func(name string) string, name string) *Node {
+	index := -1
+	if normalize != nil {
+		name = normalize(name)
+		for i, field := range n.nodes {
+			if field.bits&hellBitEscapedField == hellBitEscapedField {
+				field.unescapeField()
+			}
+			if normalize(field.data) == name {
+				index = i
+				break
+			}
+		}
+	} else if len(n.nodes) > MapUseThreshold {
+		if n.bits&hellBitUseFoldMap != hellBitUseFoldMap {
+			n.buildFoldedMap()
+		}
+
+		i, has := n.maps.folded[FoldFieldName(name)]
+		if !has {
+			return nil
+		}
+		index = i
+	} else {
+		for i, field := range n.nodes {
+			if field.bits&hellBitEscapedField == hellBitEscapedField {
+				field.unescapeField()
+			}
+			if strings.EqualFold(field.data, name) {
+				index = i
+				break
+			}
+		}
+	}
+
+	if index == -1 {
+		return nil
+	}
+
+	result := n.nodes[index].next
+	result.bits = result.bits&hellBitsDirtyReset | (n.bits & hellBitsDirtyFilter)
+	result.setIndex(index)
+
+	return result
+}
+
+// buildFoldedMap indexes object fields by folded names to speed up DigFold()
+func (n *Node) buildFoldedMap() {
+	if n.maps == nil {
+		n.maps = &fieldMaps{}
+	}
+	m := n.maps.folded
+	if m == nil {
+		m = make(map[string]int, len(n.nodes))
+		n.maps.folded = m
+	} else {
+		for field := range m {
+			delete(m, field)
+		}
+	}
+
+	for index, field := range n.nodes {
+		if field.bits&hellBitEscapedField == hellBitEscapedField {
+			field.unescapeField()
+		}
+		name := FoldFieldName(field.data)
+		if _, has := m[name]; !has {
+			m[name] = index
+		}
+	}
+	n.bits |= hellBitUseFoldMap
+}
+
+/*
+FoldFieldName returns canonical form of the name, names are equal in terms of strings.EqualFold()
+if and only if their canonical forms are equal. Lower case ASCII names are returned as is without allocations.
+*/
+func FoldFieldName(name string) string {
+	i := 0
+	for ; i < len(name); i++ {
+		c := name[i]
+		if c >= utf8.RuneSelf || ('A' <= c && c <= 'Z') {
+			break
+		}
+	}
+	if i == len(name) {
+		return name
+	}
+
+	b := make([]byte, i, len(name)+utf8.UTFMax)
+	copy(b, name[:i])
+	for _, r := range name[i:] {
+		b = utf8.AppendRune(b, foldRune(r))
+	}
+
+	return toString(b)
+}
+
+// foldRune returns the same rune for all runes of unicode.SimpleFold() orbit
+func foldRune(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}
+
+	min := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+
+	return unicode.ToLower(min)
+}
@@ -0,0 +1,119 @@
+package insaneJSON
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigFold(t *testing.T) {
+	root, err := DecodeString(`{"UserID":{"Name":"a","ΣΑΣ":"b"},"list":[{"KEY":1}],"xA":2}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	assert.Equal(t, "a", root.DigFold("userid", "NAME").AsString(), "wrong result")
+	assert.Equal(t, "b", root.DigFold("userId", "σας").AsString(), "wrong result")
+	assert.Equal(t, 1, root.DigFold("LIST", "0", "key").AsInt(), "wrong result")
+	assert.Equal(t, 2, root.DigFold("XA").AsInt(), "wrong result")
+	assert.Equal(t, root.Node, root.DigFold(), "wrong result")
+
+	assert.Nil(t, root.DigFold("user_id"), "wrong result")
+	assert.Nil(t, root.DigFold("list", "1"), "wrong result")
+	assert.Nil(t, root.DigFold("list", "x"), "wrong result")
+	assert.Nil(t, root.DigFold("xa", "a"), "wrong result")
+
+	root.DigFold("userid").Suicide()
+	assert.Equal(t, `{"xA":2,"list":[{"KEY":1}]}`, root.EncodeToString(), "found node should be deleted")
+}
+
+func TestDigFoldMap(t *testing.T) {
+	fields := make([]string, 0)
+	for i := 0; i < MapUseThreshold*2; i++ {
+		fields = append(fields, `"Field`+strconv.Itoa(i)+`":`+strconv.Itoa(i))
+	}
+	fields = append(fields, `"FIELD0":"dup"`, `"Straße":"s"`)
+	root, err := DecodeString(`{` + strings.Join(fields, ",") + `}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	for i := 0; i < MapUseThreshold*2; i++ {
+		assert.Equal(t, i, root.DigFold("fIeLd"+strconv.Itoa(i)).AsInt(), "wrong result")
+	}
+	assert.Equal(t, 0, root.DigFold("field0").AsInt(), "first field should be found")
+	assert.Equal(t, "s", root.DigFold("STRAßE").AsString(), "wrong result")
+	assert.Equal(t, hellBitUseFoldMap, root.bits&hellBitUseFoldMap, "map should be used")
+	assert.Equal(t, "dup", root.Dig("FIELD0").AsString(), "wrong result")
+	assert.Nil(t, root.Dig("field0"), "Dig() should compare names exactly")
+	assert.Equal(t, hellBitUseMap|hellBitUseFoldMap, root.bits&(hellBitUseMap|hellBitUseFoldMap), "both maps should be used")
+
+	root.AddField("NEW").MutateToInt(1)
+	assert.Equal(t, 1, root.DigFold("new").AsInt(), "added field should be found")
+
+	root.DigFold("field1").Suicide()
+	assert.Nil(t, root.DigFold("field1"), "deleted field shouldn't be found")
+	assert.Equal(t, 2, root.DigFold("field2").AsInt(), "wrong result")
+
+	root.Dig("Field2").FieldNode().MutateToField("renamed")
+	assert.Nil(t, root.DigFold("field2"), "renamed field shouldn't be found")
+	assert.Equal(t, 2, root.DigFold("RENAMED").AsInt(), "wrong result")
+
+	root.SortFields()
+	assert.Equal(t, 3, root.DigFold("field3").AsInt(), "wrong result")
+
+	root.DeleteFields("Field3")
+	assert.Nil(t, root.DigFold("field3"), "deleted field shouldn't be found")
+	assert.Equal(t, 4, root.DigFold("field4").AsInt(), "wrong result")
+}
+
+func TestDigFoldWith(t *testing.T) {
+	normalize := func(name string) string {
+		return strings.ReplaceAll(FoldFieldName(name), "_", "")
+	}
+
+	root, err := DecodeString(`{"user_id":1,"a":{"UserName":"b"}}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	assert.Equal(t, 1, root.DigFoldWith(normalize, "userId").AsInt(), "wrong result")
+	assert.Equal(t, 1, root.DigFoldWith(normalize, "USER_ID").AsInt(), "wrong result")
+	assert.Equal(t, "b", root.DigFoldWith(normalize, "a", "user_name").AsString(), "wrong result")
+	assert.Nil(t, root.DigFoldWith(normalize, "user"), "wrong result")
+	assert.Nil(t, root.DigFold("userId"), "normalizer shouldn't affect DigFold()")
+
+	fields := make([]string, 0)
+	for i := 0; i < MapUseThreshold*2; i++ {
+		fields = append(fields, `"field_`+strconv.Itoa(i)+`":`+strconv.Itoa(i))
+	}
+	err = root.DecodeString(`{` + strings.Join(fields, ",") + `}`)
+	assert.NoError(t, err, "error while decoding")
+
+	assert.Nil(t, root.DigFold("Field7"), "wrong result")
+	assert.Equal(t, 7, root.DigFoldWith(normalize, "Field7").AsInt(), "folded map shouldn't be used with normalizer")
+	assert.Equal(t, 7, root.DigFold("Field_7").AsInt(), "wrong result")
+
+	identity := func(name string) string { return name }
+	assert.Nil(t, root.DigFoldWith(identity, "Field_7"), "folded map shouldn't be used with normalizer")
+	assert.Equal(t, 7, root.DigFoldWith(identity, "field_7").AsInt(), "wrong result")
+}
+
+func TestFoldFieldName(t *testing.T) {
+	assert.Equal(t, "abc_1", FoldFieldName("abc_1"), "wrong result")
+	assert.Equal(t, "abc", FoldFieldName("AbC"), "wrong result")
+	assert.Equal(t, FoldFieldName("k"), FoldFieldName("\u212a"), "kelvin sign should be folded")
+	assert.Equal(t, FoldFieldName("s"), FoldFieldName("ſ"), "long s should be folded")
+	assert.Equal(t, FoldFieldName("σας"), FoldFieldName("ΣΑΣ"), "wrong result")
+
+	for r := rune(0); r <= unicode.MaxRune; r += 7 {
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			assert.Equal(t, foldRune(r), foldRune(f), "wrong fold for %q", r)
+		}
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		FoldFieldName("user_id")
+	})
+	assert.Equal(t, float64(0), allocs, "lower case ascii shouldn't allocate")
+}
@@ -0,0 +1,291 @@
+package insaneJSON
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+var fuzzSeeds = []string{
+	`[]`,
+	`[0,1,2,3]`,
+	`[{},{},{},{}]`,
+	`[{"1":"1"},{"1":"1"},{"1":"1"},{"1":"1"}]`,
+	`[["1","1"],["1","1"],["1","1"],["1","1"]]`,
+	`[[],0]`,
+	`["a",{"6":"5","l":[3,4]},"c","d"]`,
+	`{}`,
+	`{"a":null}`,
+	`{"a":null,"b":null,"c":null}`,
+	`{"a":null,"b":null,"c":null,"d":null}`,
+	`{"a":"a"}`,
+	`{"a":"a","b":null,"c":null,"d":null}`,
+	`{"x":{"a":"a","e":"e"}}`,
+	`{"x":{"a":"a","e":"e"},"b":null,"c":null,"d":null}`,
+	`{"x":["a","a"]}`,
+	`{"x":["a","a"],"b":null,"c":null,"d":null}`,
+	`[null]`,
+	`[null,null,null]`,
+	`["a",null,null,null]`,
+	`[{"a":"a"},null,null,null]`,
+	`[["a","a"],null,null,null]`,
+	`{"a":1,"a":2}`,
+	`{"A\n":"😀\"\\\/\b\f\r\t","\ud800":"\xff"}`,
+	`-0.5e+10`,
+	` true `,
+}
+
+// fuzzFields and fuzzJSONs are picked by mutation sequences of FuzzMutate
+var fuzzFields = []string{
+	"1", "2", "3", "4", "5", "6", "7", "8", "9", "10",
+	"11", "21", "31", "41", "51", "61", "71", "81", "91", "101",
+	"a", "b", "c", "l", "x", "", "a.b", "\"", "\\", "é",
+}
+
+var fuzzJSONs = []string{
+	"1", "2.5", "-3e2", `"s"`, "null", "true", "false", "[]", "{}",
+	`{"a":"b","c":"d"}`,
+	`{"5":"5","l":[3,4]}`,
+	`{"a":{"5":"5","l":[3,4]},"c":"d"}`,
+	`{"a":"b","c":{"5":"5","l":[3,4]}}`,
+	`{"a":{"somekey":"someval", "xxx":"yyy"},"c":"d"}`,
+	`["a","b","c","d"]`,
+	`[{"5":"5","l":[3,4]},"b","c","d"]`,
+	`["a","b","c",{"5":"5","l":[3,4]}]`,
+	`"é\n"`,
+}
+
+// addFuzzCorpus adds seeds, benchdata files and lines of the chaotic workload
+func addFuzzCorpus(f *testing.F, add func(data []byte)) {
+	for _, seed := range fuzzSeeds {
+		add([]byte(seed))
+	}
+
+	files, err := filepath.Glob("benchdata/*.json")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		add(content)
+	}
+
+	file, err := os.Open("benchdata/chaotic-workload.log")
+	if err != nil {
+		f.Fatal(err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		add(append([]byte(nil), scanner.Bytes()...))
+	}
+	if err := scanner.Err(); err != nil {
+		f.Fatal(err)
+	}
+}
+
+/*
+FuzzDecode compares decoding with encoding/json.
+Insane JSON doesn't validate numbers, strings and trailing data,
+so only JSON valid for encoding/json is compared, other inputs just shouldn't crash.
+*/
+func FuzzDecode(f *testing.F) {
+	addFuzzCorpus(f, func(data []byte) { f.Add(data) })
+
+	root := Spawn()
+	defer Release(root)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		err := root.DecodeBytes(data)
+		valid := json.Valid(data)
+		if valid && err != nil {
+			t.Fatalf("valid json isn't decoded: %s", err.Error())
+		}
+		if err != nil {
+			return
+		}
+
+		out := root.Encode(nil)
+		if !valid {
+			checkReencode(t, out)
+			return
+		}
+
+		expected := unmarshalFuzz(t, data)
+		if actual := fuzzValue(root.Node); !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("wrong decoded value:\nexpected: %#v\nactual:   %#v", expected, actual)
+		}
+
+		if !json.Valid(out) {
+			t.Fatalf("encoded json isn't valid: %s", out)
+		}
+		if actual := unmarshalFuzz(t, out); !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("wrong value after round trip:\nexpected: %#v\nactual:   %#v", expected, actual)
+		}
+	})
+}
+
+/*
+FuzzMutate applies mutation sequence to decoded JSON, every two bytes of ops are an operation and its argument.
+The result should be encoded to JSON which is decoded back to the same value and tree indexes should stay valid.
+*/
+func FuzzMutate(f *testing.F) {
+	sequences := [][]byte{
+		{},
+		{0, 0, 2, 7, 1, 0, 0, 1, 4, 0},
+		{0, 3, 0, 0, 3, 12, 5, 9, 4, 0, 0, 0, 4, 0},
+		{2, 0, 2, 31, 2, 62, 4, 0, 2, 0, 0, 0, 5, 10},
+		{3, 5, 3, 10, 0, 1, 2, 3, 0, 0, 4, 0, 4, 0, 4, 0},
+	}
+	for _, seed := range fuzzSeeds {
+		for _, ops := range sequences {
+			f.Add([]byte(seed), ops)
+		}
+	}
+
+	root := Spawn()
+	defer Release(root)
+
+	f.Fuzz(func(t *testing.T, data []byte, ops []byte) {
+		if root.DecodeBytes(data) != nil {
+			return
+		}
+		valid := json.Valid(data)
+
+		node := root.Node
+		for i := 0; i+1 < len(ops); i += 2 {
+			node = mutateFuzz(root, node, ops[i], int(ops[i+1]))
+		}
+
+		checkFuzzTree(t, root.Node)
+
+		out := root.Encode(nil)
+		if valid && !json.Valid(out) {
+			t.Fatalf("encoded json isn't valid: %s", out)
+		}
+		checkReencode(t, out)
+	})
+}
+
+func mutateFuzz(root *Root, node *Node, op byte, arg int) *Node {
+	switch op % 6 {
+	case 0:
+		// go deeper
+		switch {
+		case node.IsObject() && len(node.AsFields()) > 0:
+			return node.AsFields()[arg%len(node.AsFields())].AsFieldValue()
+		case node.IsArray() && len(node.AsArray()) > 0:
+			return node.AsArray()[arg%len(node.AsArray())]
+		}
+	case 1:
+		return root.Node
+	case 2:
+		name := fuzzFields[arg%len(fuzzFields)]
+		node.AddField(name).MutateToJSON(root, fuzzJSONs[arg%len(fuzzJSONs)])
+	case 3:
+		node.AddElement().MutateToJSON(root, fuzzJSONs[arg%len(fuzzJSONs)])
+	case 4:
+		if node != root.Node {
+			node.Suicide()
+			return root.Node
+		}
+	case 5:
+		node.MutateToJSON(root, fuzzJSONs[arg%len(fuzzJSONs)])
+	}
+
+	return node
+}
+
+// checkFuzzTree checks that every child is found by Dig() and has the right parent
+func checkFuzzTree(t *testing.T, node *Node) {
+	switch {
+	case node.IsObject():
+		for _, field := range node.AsFields() {
+			if node.Dig(field.AsString()) == nil {
+				t.Fatalf("field %q isn't found", field.AsString())
+			}
+			if field.parent != node || field.next.parent != node {
+				t.Fatalf("wrong parent of field %q", field.AsString())
+			}
+			checkFuzzTree(t, field.AsFieldValue())
+		}
+	case node.IsArray():
+		for i, element := range node.AsArray() {
+			if node.Dig(strconv.Itoa(i)) != element {
+				t.Fatalf("element %d isn't found", i)
+			}
+			if element.parent != node {
+				t.Fatalf("wrong parent of element %d", i)
+			}
+			checkFuzzTree(t, element)
+		}
+	}
+}
+
+// checkReencode checks that encoded JSON is decoded and encoded to the same bytes
+func checkReencode(t *testing.T, out []byte) {
+	root, err := DecodeBytes(out)
+	defer Release(root)
+	if err != nil {
+		t.Fatalf("encoded json isn't decoded: %s: %s", err.Error(), out)
+	}
+
+	if again := root.Encode(nil); !bytes.Equal(out, again) {
+		t.Fatalf("json is changed after decoding:\nexpected: %s\nactual:   %s", out, again)
+	}
+}
+
+func unmarshalFuzz(t *testing.T, data []byte) interface{} {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		t.Fatalf("encoding/json can't decode json: %s", err.Error())
+	}
+
+	return value
+}
+
+// fuzzValue converts node to the value encoding/json decodes, duplicated fields are overwritten like there
+func fuzzValue(node *Node) interface{} {
+	switch {
+	case node.IsObject():
+		value := make(map[string]interface{})
+		for _, field := range node.AsFields() {
+			value[fuzzString(field.AsString())] = fuzzValue(field.AsFieldValue())
+		}
+		return value
+	case node.IsArray():
+		value := make([]interface{}, 0)
+		for _, element := range node.AsArray() {
+			value = append(value, fuzzValue(element))
+		}
+		return value
+	case node.IsString():
+		return fuzzString(node.AsString())
+	case node.IsNumber():
+		return json.Number(node.AsString())
+	case node.IsTrue():
+		return true
+	case node.IsFalse():
+		return false
+	}
+
+	return nil
+}
+
+// fuzzString replaces every invalid UTF-8 byte with U+FFFD like encoding/json does
+func fuzzString(s string) string {
+	return string([]rune(s))
+}
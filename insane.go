@@ -3,13 +3,14 @@ package insaneJSON
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
-	"math/rand"
 	"reflect"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"unicode/utf16"
 	"unicode/utf8"
 	"unsafe"
@@ -27,6 +28,8 @@ import (
 // 12-35 bits – node index
 // 36-59 bits – dirty sequence
 // 60    bit  – map usage
+// 61    bit  – temporary mark of bulk operations
+// 62    bit  – folded map usage
 type hellBits uint64
 
 const (
@@ -44,8 +47,12 @@ const (
 	hellBitEscapedField  hellBits = 1 << 11
 	hellBitTypeFilter    hellBits = 1<<11 - 1
 
-	hellBitUseMap       hellBits = 1 << 60
-	hellBitsUseMapReset          = 1<<64 - 1 - hellBitUseMap
+	hellBitUseMap hellBits = 1 << 60
+
+	hellBitMark hellBits = 1 << 61
+
+	hellBitUseFoldMap hellBits = 1 << 62
+	hellBitsMapsReset          = 1<<64 - 1 - hellBitUseMap - hellBitUseFoldMap
 
 	hellBitsDirtyFilter          = 0x0FFFFFF000000000
 	hellBitsDirtyReset  hellBits = 0xF000000FFFFFFFFF
@@ -62,6 +69,16 @@ var (
 	StartNodePoolSize      = 128
 	MapUseThreshold        = 16
 	DisableBeautifulErrors = false // set to "true" for best performance, if you have many decode errors
+	EncodeFlushSize        = 32 * 1024
+	MaxArrayPadding        = 1024 // Upsert() and Unflatten() don't add more padding nulls to create an array element
+
+	encodeBufPool = sync.Pool{New: func() interface{} {
+		buf := make([]byte, 0, EncodeFlushSize*2)
+		return &buf
+	}}
+	nodesSorterPool = sync.Pool{New: func() interface{} {
+		return &nodesSorter{}
+	}}
 
 	decoderPool      = make([]*decoder, 0, 16)
 	decoderPoolIndex = -1
@@ -91,6 +108,18 @@ var (
 	ErrNotString = errors.New("node isn't a string")
 	ErrNotNumber = errors.New("node isn't a number")
 	ErrNotField  = errors.New("node isn't an object field")
+
+	ErrNotInteger     = errors.New("number isn't an integer")
+	ErrNumberOverflow = errors.New("number is out of range")
+	ErrPrecisionLoss  = errors.New("number can't be represented without precision loss")
+
+	ErrNotTime     = errors.New("node isn't a time")
+	ErrNotDuration = errors.New("node isn't a duration")
+
+	// redactor errors
+	ErrRedactWrongRule   = errors.New("redact rule should have path or value and non negative KeepLast")
+	ErrRedactWrongAction = errors.New("wrong redact action")
+	ErrRedactKeyRequired = errors.New("redactor key is required for hash action")
 )
 
 func init() {
@@ -115,12 +144,19 @@ And a special one – Field, which represents the field(key) on an objects.
 It allows to easily change field's name, checkout MutateToField() function.
 */
 type Node struct {
+	dbg    nodeDebug // empty unless insanedebug build tag is used
 	bits   hellBits
 	data   string
 	next   *Node
 	parent *Node
 	nodes  []*Node
-	fields *map[string]int
+	maps   *fieldMaps
+}
+
+// fieldMaps are indexes of object fields, they are allocated on first use and reused along with the node
+type fieldMaps struct {
+	index  map[string]int // field names for Dig()
+	folded map[string]int // normalized field names for DigFold()
 }
 
 /*
@@ -149,13 +185,26 @@ type decoder struct {
 	root      Root
 	nodePool  []*Node
 	nodeCount int
+
+	// memory usage at the moment of the last pool operation, see Stats()
+	statNodesCap int
+	statBufCap   int
+
+	// moving average of memory usage, see ReleaseTrimPolicy
+	avgBuf   float64
+	avgNodes float64
+
+	dbg decoderDebug
 }
 
 /*
 ReleaseMem sends node pool and internal buffer to GC.
 Useful to reduce memory usage after decoding big JSON.
+Checkout ReleaseTrimPolicy to do it automatically on Release().
 */
 func (r *Root) ReleaseMem() {
+	r.debugCheck()
+
 	r.ReleasePoolMem()
 	r.ReleaseBufMem()
 }
@@ -165,6 +214,8 @@ ReleasePoolMem sends node pool to GC.
 Useful to reduce memory usage after decoding big JSON.
 */
 func (r *Root) ReleasePoolMem() {
+	r.debugCheck()
+
 	r.decoder.initPool()
 }
 
@@ -173,6 +224,8 @@ ReleaseBufMem sends internal buffer to GC.
 Useful to reduce memory usage after decoding big JSON.
 */
 func (r *Root) ReleaseBufMem() {
+	r.debugCheck()
+
 	r.decoder.buf = make([]byte, 0, 0)
 }
 
@@ -180,6 +233,8 @@ func (r *Root) ReleaseBufMem() {
 BuffCap returns current size of internal buffer.
 */
 func (r *Root) BuffCap() int {
+	r.debugCheck()
+
 	return cap(r.decoder.buf)
 }
 
@@ -187,6 +242,8 @@ func (r *Root) BuffCap() int {
 PoolSize returns how many Node objects is in the pool right now.
 */
 func (r *Root) PoolSize() int {
+	r.debugCheck()
+
 	return len(r.decoder.nodePool)
 }
 
@@ -203,6 +260,7 @@ func (d *decoder) decode(json string, shouldReset bool) (*Node, error) {
 	if shouldReset {
 		d.nodeCount = 0
 		d.buf = d.buf[:0]
+		d.debugReset()
 	}
 	o := len(d.buf)
 
@@ -213,6 +271,7 @@ func (d *decoder) decode(json string, shouldReset bool) (*Node, error) {
 	nodePool := d.nodePool
 	nodePoolLen := len(nodePool)
 	nodes := d.nodeCount
+	first := nodes
 
 	root := nodePool[nodes]
 	root.parent = nil
@@ -566,6 +625,11 @@ exit:
 	root.next = nil
 	curNode.next = nil
 	d.nodeCount = nodes
+	// keep free nodes for getNode() and additional decoding, the pool can be filled up to the end here
+	if nodes > len(d.nodePool)-16 {
+		d.expandPool()
+	}
+	d.debugStamp(first, nodes)
 
 	return root, nil
 }
@@ -589,6 +653,8 @@ func (d *decoder) decodeHeadless(json string, isPooled bool) (*Root, error) {
 // slow because it allocates new byte buffer on every call
 // use Encode to reuse already created buffer and gain more performance
 func (n *Node) EncodeToByte() []byte {
+	n.debugCheck()
+
 	return n.Encode([]byte{})
 }
 
@@ -596,6 +662,8 @@ func (n *Node) EncodeToByte() []byte {
 // slow because it allocates new string on every call
 // use Encode to reuse already created buffer and gain more performance
 func (n *Node) EncodeToString() string {
+	n.debugCheck()
+
 	return toString(n.Encode([]byte{}))
 }
 
@@ -604,16 +672,79 @@ func (n *Node) EncodeToString() string {
 // mem allocations may occur only if buffer isn't long enough
 // use it for performance
 func (n *Node) Encode(out []byte) []byte {
+	n.debugCheck()
+
+	out, _ = n.encode(out, nil, nil)
+	return out
+}
+
+// EncodeWithOptions is the same as Encode, but escapes strings according to opts
+// it's slower, so use it only if consumer of JSON requires special escaping
+func (n *Node) EncodeWithOptions(out []byte, opts EncodeOptions) []byte {
+	n.debugCheck()
+
+	out, _ = n.encode(out, nil, &opts)
+	return out
+}
+
+// EncodeTo legendary insane encode function for big JSONs
+// it writes json data to w every time internal buffer exceeds EncodeFlushSize
+// so memory usage doesn't depend on JSON size
+func (n *Node) EncodeTo(w io.Writer) error {
+	n.debugCheck()
+
+	buf := encodeBufPool.Get().(*[]byte)
+	out, err := n.EncodeToBuf(w, (*buf)[:0])
+	*buf = out
+	encodeBufPool.Put(buf)
+
+	return err
+}
+
+// EncodeToWithOptions is the same as EncodeTo, but escapes strings according to opts
+func (n *Node) EncodeToWithOptions(w io.Writer, opts EncodeOptions) error {
+	n.debugCheck()
+
+	buf := encodeBufPool.Get().(*[]byte)
+	out, err := n.encode((*buf)[:0], w, &opts)
+	if err == nil {
+		out, err = flushEncoded(w, out)
+	}
+	*buf = out
+	encodeBufPool.Put(buf)
+
+	return err
+}
+
+// EncodeToBuf is the same as EncodeTo, but uses provided buffer
+// returns buffer back, so it can be reused for the next call
+func (n *Node) EncodeToBuf(w io.Writer, buf []byte) ([]byte, error) {
+	n.debugCheck()
+
+	out, err := n.encode(buf[:0], w, nil)
+	if err != nil {
+		return out, err
+	}
+
+	return flushEncoded(w, out)
+}
+
+// encode writes data to w if it isn't nil and buffer is full
+// default escaping is used if opts is nil
+func (n *Node) encode(out []byte, w io.Writer, opts *EncodeOptions) ([]byte, error) {
+	var err error
+	// escaped strings are unescaped here for re-escaping, since encoding shouldn't change nodes
+	var scratch []byte
 	s := 0
 	curNode := n
 	topNode := n
 
 	if len(curNode.nodes) == 0 {
 		if curNode.bits&hellBitObject == hellBitObject {
-			return append(out, "{}"...)
+			return append(out, "{}"...), nil
 		}
 		if curNode.bits&hellBitArray == hellBitArray {
-			return append(out, "[]"...)
+			return append(out, "[]"...), nil
 		}
 	}
 
@@ -631,8 +762,10 @@ encodeSkip:
 		topNode = curNode
 		out = append(out, '{')
 		curNode = curNode.nodes[0]
-		if curNode.bits&hellBitField == hellBitField {
-			out = escapeString(out, curNode.data)
+		if opts != nil {
+			out = opts.appendField(out, curNode, &scratch)
+		} else if curNode.bits&hellBitField == hellBitField {
+			out = escapeString(out, curNode.data, nil)
 			out = append(out, ':')
 		} else {
 			out = append(out, curNode.data...)
@@ -654,9 +787,13 @@ encodeSkip:
 	case hellBitNumber:
 		out = append(out, curNode.data...)
 	case hellBitString:
-		out = escapeString(out, curNode.data)
+		out = escapeString(out, curNode.data, opts)
 	case hellBitEscapedString:
-		out = append(out, curNode.data...)
+		if opts != nil {
+			out = opts.appendEscapedString(out, curNode, &scratch)
+		} else {
+			out = append(out, curNode.data...)
+		}
 	case hellBitFalse:
 		out = append(out, "false"...)
 	case hellBitTrue:
@@ -667,6 +804,12 @@ encodeSkip:
 pop:
 	curNode = curNode.next
 popSkip:
+	if w != nil && len(out) >= EncodeFlushSize {
+		out, err = flushEncoded(w, out)
+		if err != nil {
+			return out, err
+		}
+	}
 	if topNode.bits&hellBitArray == hellBitArray {
 		if curNode.bits&hellBitArrayEnd == hellBitArrayEnd {
 			out = append(out, "]"...)
@@ -674,7 +817,7 @@ popSkip:
 			topNode = topNode.parent
 			s--
 			if s == 0 {
-				return out
+				return out, nil
 			}
 			goto pop
 		}
@@ -686,13 +829,15 @@ popSkip:
 			topNode = topNode.parent
 			s--
 			if s == 0 {
-				return out
+				return out, nil
 			}
 			goto pop
 		}
 		out = append(out, ","...)
-		if curNode.bits&hellBitField == hellBitField {
-			out = escapeString(out, curNode.data)
+		if opts != nil {
+			out = opts.appendField(out, curNode, &scratch)
+		} else if curNode.bits&hellBitField == hellBitField {
+			out = escapeString(out, curNode.data, nil)
 			out = append(out, ':')
 		} else {
 			out = append(out, curNode.data...)
@@ -700,12 +845,27 @@ popSkip:
 		curNode = curNode.next
 		goto encodeSkip
 	} else {
-		return out
+		return out, nil
+	}
+}
+
+func flushEncoded(w io.Writer, out []byte) ([]byte, error) {
+	if len(out) == 0 {
+		return out, nil
+	}
+
+	l, err := w.Write(out)
+	if err == nil && l != len(out) {
+		err = io.ErrShortWrite
 	}
+
+	return out[:0], err
 }
 
 // Dig legendary insane dig function
 func (n *Node) Dig(path ...string) *Node {
+	n.debugCheck()
+
 	if n == nil {
 		return nil
 	}
@@ -729,28 +889,11 @@ get:
 
 	if len(node.nodes) > MapUseThreshold {
 		if node.bits&hellBitUseMap != hellBitUseMap {
-			var m map[string]int
-			if node.fields == nil {
-				m = make(map[string]int, len(node.nodes))
-				node.fields = &m
-			} else {
-				m = *node.fields
-				for field := range m {
-					delete(m, field)
-				}
-			}
-
-			for index, field := range node.nodes {
-				if field.bits&hellBitEscapedField == hellBitEscapedField {
-					field.unescapeField()
-				}
-				m[field.data] = index
-			}
-			node.bits |= hellBitUseMap
+			node.buildFieldsMap()
 		}
 
 		if node.bits&hellBitUseMap == hellBitUseMap {
-			index, has := (*node.fields)[curField]
+			index, has := node.maps.index[curField]
 			if !has {
 				return nil
 			}
@@ -808,17 +951,44 @@ getArray:
 	goto get
 }
 
+// buildFieldsMap indexes object fields to speed up Dig()
+func (n *Node) buildFieldsMap() {
+	if n.maps == nil {
+		n.maps = &fieldMaps{}
+	}
+	m := n.maps.index
+	if m == nil {
+		m = make(map[string]int, len(n.nodes))
+		n.maps.index = m
+	} else {
+		for field := range m {
+			delete(m, field)
+		}
+	}
+
+	for index, field := range n.nodes {
+		if field.bits&hellBitEscapedField == hellBitEscapedField {
+			field.unescapeField()
+		}
+		m[field.data] = index
+	}
+	n.bits |= hellBitUseMap
+}
+
 func (d *decoder) getNode() *Node {
 	node := d.nodePool[d.nodeCount]
 	d.nodeCount++
 	if d.nodeCount > len(d.nodePool)-16 {
 		d.expandPool()
 	}
+	d.debugStampNode(node)
 
 	return node
 }
 
 func (n *Node) DigStrict(path ...string) (*StrictNode, error) {
+	n.debugCheck()
+
 	result := n.Dig(path...)
 	if result == nil {
 		return nil, ErrNotFound
@@ -828,10 +998,14 @@ func (n *Node) DigStrict(path ...string) (*StrictNode, error) {
 }
 
 func (n *Node) AddField(name string) *Node {
+	n.debugCheck()
+
 	return n.AddFieldNoAlloc(nil, name)
 }
 
 func (n *Node) AddFieldNoAlloc(root *Root, name string) *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitObject != hellBitObject {
 		return nil
 	}
@@ -867,8 +1041,9 @@ func (n *Node) AddFieldNoAlloc(root *Root, name string) *Node {
 	n.nodes = append(n.nodes, newField)
 
 	if n.bits&hellBitUseMap == hellBitUseMap {
-		(*n.fields)[name] = l
+		n.maps.index[name] = l
 	}
+	n.bits &^= hellBitUseFoldMap
 
 	return newNull
 }
@@ -901,14 +1076,20 @@ func (n *Node) addElement(root *Root) *Node {
 }
 
 func (n *Node) AddElement() *Node {
+	n.debugCheck()
+
 	return n.addElement(nil)
 }
 
 func (n *Node) AddElementNoAlloc(root *Root) *Node {
+	n.debugCheck()
+
 	return n.addElement(root)
 }
 
 func (n *Node) InsertElement(pos int) *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitArray != hellBitArray {
 		return nil
 	}
@@ -941,23 +1122,42 @@ func (n *Node) InsertElement(pos int) *Node {
 		n.nodes[pos-1].next = newNull
 	}
 
-	leftPart := n.nodes[:pos]
-	rightPart := n.nodes[pos:]
-
-	n.nodes = make([]*Node, 0, 0)
-	n.nodes = append(n.nodes, leftPart...)
-	n.nodes = append(n.nodes, newNull)
-	n.nodes = append(n.nodes, rightPart...)
+	n.nodes = append(n.nodes, nil)
+	if pos != l {
+		// indexes of next elements are shifted
+		n.bits += hellBitsDirtyStep
+		copy(n.nodes[pos+1:], n.nodes[pos:l])
+	}
+	n.nodes[pos] = newNull
 
 	return newNull
 }
 
 // Suicide legendary insane suicide function
 func (n *Node) Suicide() {
+	n.debugCheck()
+
+	n.suicide(false)
+}
+
+// SuicideOrdered is the same as Suicide(), but keeps order of other object fields, it makes deletion O(n)
+func (n *Node) SuicideOrdered() {
+	n.debugCheck()
+
+	n.suicide(true)
+}
+
+func (n *Node) suicide(ordered bool) {
 	if n == nil {
 		return
 	}
 
+	// field dies along with its value
+	if n.bits&(hellBitField|hellBitEscapedField) != 0 {
+		n.next.suicide(ordered)
+		return
+	}
+
 	owner := n.parent
 
 	// root is immortal, sorry
@@ -973,6 +1173,7 @@ func (n *Node) Suicide() {
 
 	// mark owner as dirty
 	owner.bits += hellBitsDirtyStep
+	owner.bits &^= hellBitUseFoldMap
 
 	switch owner.bits & hellBitTypeFilter {
 	case hellBitObject:
@@ -982,12 +1183,17 @@ func (n *Node) Suicide() {
 			owner.nodes = owner.nodes[:0]
 
 			if owner.bits&hellBitUseMap == hellBitUseMap {
-				delete(*owner.fields, delField.data)
+				delete(owner.maps.index, delField.data)
 			}
 
 			return
 		}
 
+		if ordered {
+			owner.deleteFieldOrdered(delIndex)
+			return
+		}
+
 		lastField := owner.nodes[moveIndex]
 		owner.nodes[delIndex] = lastField
 
@@ -1001,9 +1207,9 @@ func (n *Node) Suicide() {
 		}
 
 		if owner.bits&hellBitUseMap == hellBitUseMap {
-			delete(*owner.fields, delField.data)
+			delete(owner.maps.index, delField.data)
 			if delIndex != moveIndex {
-				(*owner.fields)[lastField.data] = delIndex
+				owner.maps.index[lastField.data] = delIndex
 			}
 		}
 		owner.nodes = owner.nodes[:len(owner.nodes)-1]
@@ -1069,6 +1275,8 @@ func (n *Node) findSelf() int {
 // ******************** //
 
 func (n *Node) MergeWith(node *Node) *Node {
+	n.debugCheck()
+
 	if n == nil || node == nil {
 		return n
 	}
@@ -1087,7 +1295,10 @@ func (n *Node) MergeWith(node *Node) *Node {
 }
 
 // MutateToNode it isn't safe function, if you create node cycle, encode() may freeze
+// children are shared with the node, use DeepCopy() to copy them or MoveTo() to move the node safely
 func (n *Node) MutateToNode(node *Node) *Node {
+	n.debugCheck()
+
 	if n == nil || node == nil {
 		return n
 	}
@@ -1095,7 +1306,7 @@ func (n *Node) MutateToNode(node *Node) *Node {
 	n.bits = node.bits
 	n.data = node.data
 	if node.bits&hellBitObject == hellBitObject || node.bits&hellBitArray == hellBitArray {
-		n.bits &= hellBitsUseMapReset
+		n.bits &= hellBitsMapsReset
 		n.nodes = append((n.nodes)[:0], node.nodes...)
 		for _, child := range node.nodes {
 			child.parent = n
@@ -1109,6 +1320,8 @@ func (n *Node) MutateToNode(node *Node) *Node {
 }
 
 func (n *Node) MutateToJSON(root *Root, json string) *Node {
+	n.debugCheck()
+
 	if n == nil {
 		return n
 	}
@@ -1128,15 +1341,18 @@ func (n *Node) MutateToJSON(root *Root, json string) *Node {
 // root.AsField("a").MutateToField("new_name")
 // root.Encode() will be {"new_name":"a","b":"b"}
 func (n *Node) MutateToField(newFieldName string) *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField != hellBitField {
 		return n
 	}
 
 	parent := n.parent
+	parent.bits &^= hellBitUseFoldMap
 	if parent.bits&hellBitUseMap == hellBitUseMap {
-		x := (*parent.fields)[n.data]
-		delete(*parent.fields, n.data)
-		(*parent.fields)[newFieldName] = x
+		x := parent.maps.index[n.data]
+		delete(parent.maps.index, n.data)
+		parent.maps.index[newFieldName] = x
 	}
 
 	n.data = newFieldName
@@ -1145,6 +1361,8 @@ func (n *Node) MutateToField(newFieldName string) *Node {
 }
 
 func (n *Node) MutateToInt(value int) *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField == hellBitField {
 		return n
 	}
@@ -1156,6 +1374,8 @@ func (n *Node) MutateToInt(value int) *Node {
 }
 
 func (n *Node) MutateToInt64(value int64) *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField == hellBitField {
 		return n
 	}
@@ -1167,6 +1387,8 @@ func (n *Node) MutateToInt64(value int64) *Node {
 }
 
 func (n *Node) MutateToUint64(value uint64) *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField == hellBitField {
 		return n
 	}
@@ -1178,6 +1400,8 @@ func (n *Node) MutateToUint64(value uint64) *Node {
 }
 
 func (n *Node) MutateToFloat(value float64) *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField == hellBitField {
 		return n
 	}
@@ -1189,6 +1413,8 @@ func (n *Node) MutateToFloat(value float64) *Node {
 }
 
 func (n *Node) MutateToBool(value bool) *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField == hellBitField {
 		return n
 	}
@@ -1203,6 +1429,8 @@ func (n *Node) MutateToBool(value bool) *Node {
 }
 
 func (n *Node) MutateToNull() *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField == hellBitField {
 		return n
 	}
@@ -1213,6 +1441,8 @@ func (n *Node) MutateToNull() *Node {
 }
 
 func (n *Node) MutateToString(value string) *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField == hellBitField {
 		return nil
 	}
@@ -1224,6 +1454,8 @@ func (n *Node) MutateToString(value string) *Node {
 }
 
 func (n *Node) MutateToEscapedString(value string) *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField == hellBitField {
 		return nil
 	}
@@ -1236,6 +1468,8 @@ func (n *Node) MutateToEscapedString(value string) *Node {
 
 // MutateToBytes mutate to a string and use byte slice as value. It doesn't copy data, so modifications of a slice will change result JSON.
 func (n *Node) MutateToBytes(value []byte) *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField == hellBitField {
 		return nil
 	}
@@ -1248,6 +1482,8 @@ func (n *Node) MutateToBytes(value []byte) *Node {
 
 // MutateToBytes mutate to a string and use byte slice as value. It copies data, so modification of a slice won't change result JSON.
 func (n *Node) MutateToBytesCopy(root *Root, value []byte) *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField == hellBitField {
 		return nil
 	}
@@ -1262,6 +1498,8 @@ func (n *Node) MutateToBytesCopy(root *Root, value []byte) *Node {
 }
 
 func (n *Node) MutateToObject() *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField == hellBitField {
 		return n
 	}
@@ -1273,6 +1511,8 @@ func (n *Node) MutateToObject() *Node {
 }
 
 func (n *Node) MutateToArray() *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField == hellBitField {
 		return n
 	}
@@ -1284,10 +1524,14 @@ func (n *Node) MutateToArray() *Node {
 }
 
 func (n *Node) MutateToStrict() *StrictNode {
+	n.debugCheck()
+
 	return &StrictNode{n}
 }
 
 func (n *Node) DigField(path ...string) *Node {
+	n.debugCheck()
+
 	if n == nil || len(path) == 0 {
 		return nil
 	}
@@ -1300,7 +1544,11 @@ func (n *Node) DigField(path ...string) *Node {
 	return n.nodes[node.getIndex()]
 }
 
+// AsFields returns internal slice of fields, so it's invalidated by AddField()/Suicide()
+// use AppendFields() to delete or add fields while iterating
 func (n *Node) AsFields() []*Node {
+	n.debugCheck()
+
 	if n == nil {
 		return make([]*Node, 0, 0)
 	}
@@ -1319,6 +1567,8 @@ func (n *Node) AsFields() []*Node {
 }
 
 func (n *StrictNode) AsFields() ([]*Node, error) {
+	n.debugCheck()
+
 	if n.bits&hellBitObject != hellBitObject {
 		return nil, ErrNotObject
 	}
@@ -1333,6 +1583,8 @@ func (n *StrictNode) AsFields() ([]*Node, error) {
 }
 
 func (n *Node) AsFieldValue() *Node {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField != hellBitField {
 		return nil
 	}
@@ -1341,6 +1593,8 @@ func (n *Node) AsFieldValue() *Node {
 }
 
 func (n *StrictNode) AsFieldValue() (*Node, error) {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitField != hellBitField {
 		return nil, ErrNotField
 	}
@@ -1348,7 +1602,11 @@ func (n *StrictNode) AsFieldValue() (*Node, error) {
 	return n.next, nil
 }
 
+// AsArray returns internal slice of elements, so it's invalidated by AddElement()/Suicide()
+// use AppendElements() to delete or add elements while iterating
 func (n *Node) AsArray() []*Node {
+	n.debugCheck()
+
 	if n == nil {
 		return make([]*Node, 0, 0)
 	}
@@ -1360,6 +1618,8 @@ func (n *Node) AsArray() []*Node {
 }
 
 func (n *StrictNode) AsArray() ([]*Node, error) {
+	n.debugCheck()
+
 	if n == nil || n.bits&hellBitArray != hellBitArray {
 		return nil, ErrNotArray
 	}
@@ -1389,6 +1649,8 @@ func (n *Node) unescapeField() {
 }
 
 func (n *Node) AsString() string {
+	n.debugCheck()
+
 	if n == nil {
 		return ""
 	}
@@ -1417,10 +1679,14 @@ func (n *Node) AsString() string {
 }
 
 func (n *Node) AsBytes() []byte {
+	n.debugCheck()
+
 	return toByte(n.AsString())
 }
 
 func (n *StrictNode) AsBytes() ([]byte, error) {
+	n.debugCheck()
+
 	s, err := n.AsString()
 	if err != nil {
 		return nil, err
@@ -1430,6 +1696,8 @@ func (n *StrictNode) AsBytes() ([]byte, error) {
 }
 
 func (n *StrictNode) AsString() (string, error) {
+	n.debugCheck()
+
 	if n.bits&hellBitEscapedField == hellBitEscapedField {
 		panic("insane json really goes outta its mind")
 	}
@@ -1446,13 +1714,15 @@ func (n *StrictNode) AsString() (string, error) {
 }
 
 func (n *Node) AsEscapedString() string {
+	n.debugCheck()
+
 	if n == nil {
 		return ""
 	}
 
 	switch n.bits & hellBitTypeFilter {
 	case hellBitString:
-		return toString(escapeString(make([]byte, 0, len(n.data)), n.data))
+		return toString(escapeString(make([]byte, 0, len(n.data)), n.data, nil))
 	case hellBitEscapedString:
 		return n.data
 	case hellBitNumber:
@@ -1473,13 +1743,15 @@ func (n *Node) AsEscapedString() string {
 }
 
 func (n *Node) AppendEscapedString(out []byte) []byte {
+	n.debugCheck()
+
 	if n == nil {
 		return out
 	}
 
 	switch n.bits & hellBitTypeFilter {
 	case hellBitString:
-		return escapeString(out, n.data)
+		return escapeString(out, n.data, nil)
 	case hellBitEscapedString, hellBitNumber, hellBitField:
 		return append(out, n.data...)
 	case hellBitTrue:
@@ -1496,6 +1768,8 @@ func (n *Node) AppendEscapedString(out []byte) []byte {
 }
 
 func (n *StrictNode) AsEscapedString() (string, error) {
+	n.debugCheck()
+
 	if n.bits&hellBitEscapedField == hellBitEscapedField {
 		panic("insane json really goes outta its mind")
 	}
@@ -1508,10 +1782,12 @@ func (n *StrictNode) AsEscapedString() (string, error) {
 		return n.data, nil
 	}
 
-	return toString(escapeString(make([]byte, 0, len(n.data)), n.data)), nil
+	return toString(escapeString(make([]byte, 0, len(n.data)), n.data, nil)), nil
 }
 
 func (n *Node) AsBool() bool {
+	n.debugCheck()
+
 	if n == nil {
 		return false
 	}
@@ -1540,6 +1816,8 @@ func (n *Node) AsBool() bool {
 }
 
 func (n *StrictNode) AsBool() (bool, error) {
+	n.debugCheck()
+
 	if n == nil || (n.bits&hellBitTrue != hellBitTrue && n.bits&hellBitFalse != hellBitFalse) {
 		return false, ErrNotBool
 	}
@@ -1548,6 +1826,8 @@ func (n *StrictNode) AsBool() (bool, error) {
 }
 
 func (n *Node) AsInt() int {
+	n.debugCheck()
+
 	if n == nil {
 		return 0
 	}
@@ -1581,17 +1861,15 @@ func (n *Node) AsInt() int {
 }
 
 func (n *StrictNode) AsInt() (int, error) {
-	if n == nil || n.bits&hellBitNumber != hellBitNumber {
-		return 0, ErrNotNumber
-	}
-	num := decodeInt64(n.data)
-	if num == 0 && n.data != "0" {
-		return 0, ErrNotNumber
-	}
-	return int(num), nil
+	n.debugCheck()
+
+	num, err := n.asInt(strconv.IntSize)
+	return int(num), err
 }
 
 func (n *Node) AsUint64() uint64 {
+	n.debugCheck()
+
 	if n == nil {
 		return 0
 	}
@@ -1625,17 +1903,14 @@ func (n *Node) AsUint64() uint64 {
 }
 
 func (n *StrictNode) AsUint64() (uint64, error) {
-	if n == nil || n.bits&hellBitNumber != hellBitNumber {
-		return 0, ErrNotNumber
-	}
-	num := decodeUint64(n.data)
-	if num == 0 && n.data != "0" {
-		return 0, ErrNotNumber
-	}
-	return num, nil
+	n.debugCheck()
+
+	return n.asUint(64)
 }
 
 func (n *Node) AsInt64() int64 {
+	n.debugCheck()
+
 	if n == nil {
 		return 0
 	}
@@ -1669,17 +1944,14 @@ func (n *Node) AsInt64() int64 {
 }
 
 func (n *StrictNode) AsInt64() (int64, error) {
-	if n == nil || n.bits&hellBitNumber != hellBitNumber {
-		return 0, ErrNotNumber
-	}
-	num := decodeInt64(n.data)
-	if num == 0 && n.data != "0" {
-		return 0, ErrNotNumber
-	}
-	return num, nil
+	n.debugCheck()
+
+	return n.asInt(64)
 }
 
 func (n *Node) AsFloat() float64 {
+	n.debugCheck()
+
 	switch n.bits & hellBitTypeFilter {
 	case hellBitString:
 		return decodeFloat64(n.data)
@@ -1703,51 +1975,83 @@ func (n *Node) AsFloat() float64 {
 	}
 }
 
+// AsFloat returns ErrPrecisionLoss along with the nearest float if number can't be represented exactly
 func (n *StrictNode) AsFloat() (float64, error) {
-	if n == nil || n.bits&hellBitNumber != hellBitNumber {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitNumber != hellBitNumber || !isNumber(n.data) {
 		return 0, ErrNotNumber
 	}
 
-	return decodeFloat64(n.data), nil
+	// slow but precise parsing is required to detect precision loss correctly
+	num, err := strconv.ParseFloat(n.data, 64)
+	if err != nil {
+		return 0, ErrNumberOverflow
+	}
+	if !isExactFloat(n.data, num) {
+		return num, ErrPrecisionLoss
+	}
+
+	return num, nil
 }
 
 func (n *Node) IsObject() bool {
+	n.debugCheck()
+
 	return n != nil && n.bits&hellBitObject == hellBitObject
 }
 
 func (n *Node) IsArray() bool {
+	n.debugCheck()
+
 	return n != nil && n.bits&hellBitArray == hellBitArray
 }
 
 func (n *Node) IsNumber() bool {
+	n.debugCheck()
+
 	return n != nil && n.bits&hellBitNumber == hellBitNumber
 }
 
 func (n *Node) IsString() bool {
+	n.debugCheck()
+
 	return n != nil && (n.bits&hellBitString == hellBitString || n.bits&hellBitEscapedString == hellBitEscapedString)
 }
 
 func (n *Node) IsTrue() bool {
+	n.debugCheck()
+
 	return n != nil && n.bits&hellBitTrue == hellBitTrue
 }
 
 func (n *Node) IsFalse() bool {
+	n.debugCheck()
+
 	return n != nil && n.bits&hellBitFalse == hellBitFalse
 }
 
 func (n *Node) IsNull() bool {
+	n.debugCheck()
+
 	return n != nil && n.bits&hellBitNull == hellBitNull
 }
 
 func (n *Node) IsField() bool {
+	n.debugCheck()
+
 	return n != nil && n.bits&hellBitField == hellBitField
 }
 
 func (n *Node) IsNil() bool {
+	n.debugCheck()
+
 	return n == nil
 }
 
 func (n *Node) TypeStr() string {
+	n.debugCheck()
+
 	if n == nil {
 		return "nil"
 	}
@@ -1810,6 +2114,8 @@ func (d *decoder) initPool() {
 }
 
 func (d *decoder) expandPool() []*Node {
+	atomic.AddUint64(&poolExpansions, 1)
+
 	c := cap(d.nodePool)
 	for i := 0; i < c; i++ {
 		d.nodePool = append(d.nodePool, &Node{})
@@ -1830,18 +2136,25 @@ func getFromPool() *decoder {
 		decoderPool = append(decoderPool, decoder)
 	}
 
-	return decoderPool[decoderPoolIndex]
+	d := decoderPool[decoderPoolIndex]
+	d.updateStats()
+
+	return d
 }
 
 func backToPool(d *decoder) {
 	decoderPoolMu.Lock()
 	defer decoderPoolMu.Unlock()
 
+	d.updateStats()
+
 	decoderPool[d.id] = decoderPool[decoderPoolIndex]
 	decoderPool[d.id].id = d.id
 
 	d.id = decoderPoolIndex
 	decoderPool[decoderPoolIndex] = d
+	// released decoder must be replaced before other goroutine gets it from the pool
+	d.debugRetire()
 
 	decoderPoolIndex--
 }
@@ -1869,6 +2182,8 @@ func DecodeFile(fileName string) (*Root, error) {
 
 // Clear makes Root empty object
 func (r *Root) Clear() {
+	r.debugCheck()
+
 	if r == nil {
 		return
 	}
@@ -1878,6 +2193,8 @@ func (r *Root) Clear() {
 
 // DecodeBytes clears Root and decodes new JSON. Useful for reusing Root to reduce allocations.
 func (r *Root) DecodeBytes(jsonBytes []byte) error {
+	r.debugCheck()
+
 	if r == nil {
 		return ErrRootIsNil
 	}
@@ -1888,6 +2205,8 @@ func (r *Root) DecodeBytes(jsonBytes []byte) error {
 
 // DecodeString clears Root and decodes new JSON. Useful for reusing Root to reduce allocations.
 func (r *Root) DecodeString(json string) error {
+	r.debugCheck()
+
 	if r == nil {
 		return ErrRootIsNil
 	}
@@ -1898,6 +2217,8 @@ func (r *Root) DecodeString(json string) error {
 
 // DecodeFile clears Root and decodes new JSON. Useful for reusing Root to reduce allocations.
 func (r *Root) DecodeFile(fileName string) error {
+	r.debugCheck()
+
 	if r == nil {
 		return ErrRootIsNil
 	}
@@ -1914,6 +2235,8 @@ func (r *Root) DecodeFile(fileName string) error {
 
 // DecodeBytesAdditional doesn't clean Root, uses Root node pool to decode JSON
 func (r *Root) DecodeBytesAdditional(jsonBytes []byte) (*Node, error) {
+	r.debugCheck()
+
 	if r == nil {
 		return nil, ErrRootIsNil
 	}
@@ -1923,6 +2246,8 @@ func (r *Root) DecodeBytesAdditional(jsonBytes []byte) (*Node, error) {
 
 // DecodeStringAdditional doesn't clean Root, uses Root node pool to decode JSON
 func (r *Root) DecodeStringAdditional(json string) (*Node, error) {
+	r.debugCheck()
+
 	if r == nil {
 		return nil, ErrRootIsNil
 	}
@@ -1935,6 +2260,8 @@ func Release(root *Root) {
 		return
 	}
 
+	root.decoder.debugRelease()
+	root.decoder.trim()
 	backToPool(root.decoder)
 }
 
@@ -1960,8 +2287,18 @@ func unescapeStr(s string) string {
 		return s
 	}
 
-	b := toByte(s)
-	b = b[:n]
+	// unescaped string isn't longer, so it's written in place
+	return toString(appendUnescaped(toByte(s)[:n], s[n:]))
+}
+
+// appendUnescaped appends unescaped s to b, b may share memory with s if it doesn't go ahead of s
+func appendUnescaped(b []byte, s string) []byte {
+	n := strings.IndexByte(s, '\\')
+	if n < 0 {
+		return append(b, s...)
+	}
+
+	b = append(b, s[:n]...)
 	s = s[n+1:]
 	for len(s) > 0 {
 		ch := s[0]
@@ -1996,7 +2333,7 @@ func unescapeStr(s string) string {
 			}
 			s = s[4:]
 			if !utf16.IsSurrogate(rune(x)) {
-				b = append(b, string(rune(x))...)
+				b = utf8.AppendRune(b, rune(x))
 				break
 			}
 
@@ -2012,7 +2349,7 @@ func unescapeStr(s string) string {
 				break
 			}
 			r := utf16.DecodeRune(rune(x), rune(x1))
-			b = append(b, string(r)...)
+			b = utf8.AppendRune(b, r)
 			s = s[6:]
 		default:
 			b = append(b, '\\', ch)
@@ -2025,11 +2362,18 @@ func unescapeStr(s string) string {
 		b = append(b, s[:n]...)
 		s = s[n+1:]
 	}
-	return toString(b)
+	return b
 }
 
-func escapeString(out []byte, st string) []byte {
-	if !shouldEscape(st) {
+// escapeString escapes st like encoding/json does if it contains quotes, backslashes or control characters,
+// or non ASCII characters in ASCIIOnly mode, nil opts escape like Encode()
+func escapeString(out []byte, st string, opts *EncodeOptions) []byte {
+	o := EncodeOptions{}
+	if opts != nil {
+		o = *opts
+	}
+
+	if !shouldEscape(st, o.ASCIIOnly) {
 		out = append(out, '"')
 		out = append(out, st...)
 		out = append(out, '"')
@@ -2041,7 +2385,7 @@ func escapeString(out []byte, st string) []byte {
 	start := 0
 	for i := 0; i < len(s); {
 		if b := s[i]; b < utf8.RuneSelf {
-			if 0x20 <= b && b != '\\' && b != '"' && b != '<' && b != '>' && b != '&' {
+			if 0x20 <= b && b != '\\' && b != '"' && (o.DisableHTMLEscape || (b != '<' && b != '>' && b != '&')) {
 				i++
 				continue
 			}
@@ -2070,6 +2414,10 @@ func escapeString(out []byte, st string) []byte {
 
 		c, size := utf8.DecodeRune(s[i:])
 		if c == utf8.RuneError && size == 1 {
+			if o.KeepInvalidUTF8 && !o.ASCIIOnly {
+				i++
+				continue
+			}
 			if start < i {
 				out = append(out, s[start:i]...)
 			}
@@ -2079,12 +2427,11 @@ func escapeString(out []byte, st string) []byte {
 			continue
 		}
 
-		if c == '\u2028' || c == '\u2029' {
+		if o.ASCIIOnly || c == '\u2028' || c == '\u2029' {
 			if start < i {
 				out = append(out, s[start:i]...)
 			}
-			out = append(out, "\\u202"...)
-			out = append(out, hex[c&0xF])
+			out = appendEscapedRune(out, c)
 			i += size
 			start = i
 			continue
@@ -2099,14 +2446,14 @@ func escapeString(out []byte, st string) []byte {
 	return out
 }
 
-func shouldEscape(s string) bool {
+func shouldEscape(s string, asciiOnly bool) bool {
 	if strings.IndexByte(s, '"') >= 0 || strings.IndexByte(s, '\\') >= 0 {
 		return true
 	}
 
 	l := len(s)
 	for i := 0; i < l; i++ {
-		if s[i] < 0x20 {
+		if s[i] < 0x20 || asciiOnly && s[i] >= utf8.RuneSelf {
 			return true
 		}
 	}
@@ -2328,93 +2675,6 @@ func decodeFloat64(s string) float64 {
 	return 0
 }
 
-var fuzzRoot *Root = nil
-
-func Fuzz(data []byte) int {
-	if fuzzRoot == nil {
-		fuzzRoot = Spawn()
-	}
-	err := fuzzRoot.DecodeBytes(data)
-	if err != nil {
-		return -1
-	}
-
-	fields := []string{
-		"1", "2", "3", "4", "5", "6", "7", "8", "9", "10",
-		"11", "21", "31", "41", "51", "61", "71", "81", "91", "101",
-		"111", "211", "311", "411", "511", "611", "711", "811", "911", "1011",
-	}
-	jsons := []string{
-		"1", "2", "3", "4", "5",
-		`{"a":"b","c":"d"}`,
-		`{"5":"5","l":[3,4]}`,
-		`{"a":{"5":"5","l":[3,4]},"c":"d"}`,
-		`{"a":"b","c":"d"}`,
-		`{"5":"5","l":[3,4]}`,
-		`{"a":"b","c":{"5":"5","l":[3,4]}}`,
-		`{"a":{"somekey":"someval", "xxx":"yyy"},"c":"d"}`,
-		`{"5":"5","l":[3,4]}`,
-		`["a","b","c","d"]`,
-		`{"5":"5","l":[3,4]}`,
-		`[{"5":"5","l":[3,4]},"b","c","d"]`,
-		`["a","b","c","d"]`,
-		`{"5":"5","l":[3,4]}`,
-		`["a","b","c",{"5":"5","l":[3,4]}]`,
-	}
-
-	node := fuzzRoot.Node
-
-	for i := 0; i < 1; i++ {
-		for j := 0; j < 100; j++ {
-			if node.IsObject() {
-				fields := node.AsFields()
-				if len(fields) == 0 {
-					break
-				}
-				node = node.Dig(fields[rand.Int()%len(fields)].AsString())
-				continue
-			}
-			if node.IsArray() {
-				fields := node.AsArray()
-				if len(fields) == 0 {
-					break
-				}
-				node = node.Dig(strconv.Itoa(rand.Int() % len(fields)))
-				continue
-			}
-
-			node.AddField(fields[rand.Int()%len(fields)]).MutateToJSON(fuzzRoot, jsons[rand.Int()%len(jsons)])
-			break
-		}
-		for j := 0; j < 200; j++ {
-			if node.IsObject() {
-				fields := node.AsFields()
-				if len(fields) == 0 {
-					break
-				}
-				node = node.Dig(fields[rand.Int()%len(fields)].AsString())
-				continue
-			}
-			if node.IsArray() {
-				fields := node.AsArray()
-				if len(fields) == 0 {
-					break
-				}
-				node = node.Dig(strconv.Itoa(rand.Int() % len(fields)))
-				continue
-			}
-
-			node.Suicide()
-			node = fuzzRoot.Node
-			break
-		}
-	}
-
-	fuzzRoot.EncodeToString()
-
-	return 1
-}
-
 func insaneErr(err error, json string, offset int) error {
 	if DisableBeautifulErrors {
 		return err
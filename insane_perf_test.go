@@ -261,7 +261,7 @@ func BenchmarkValueEscapeString(b *testing.B) {
 	out := make([]byte, 0, 0)
 	for i := 0; i < b.N; i++ {
 		for _, test := range tests {
-			out = escapeString(out[:0], test.s)
+			out = escapeString(out[:0], test.s, nil)
 		}
 	}
 }
\ No newline at end of file
@@ -1,7 +1,9 @@
 package insaneJSON
 
 import (
-	"math/rand"
+	"bytes"
+	"errors"
+	"io"
 	"strconv"
 	"strings"
 	"testing"
@@ -70,6 +72,21 @@ func TestDecodeAdditional(t *testing.T) {
 	assert.Equal(t, 1, node.Dig("1").AsInt(), "wrong node value")
 }
 
+// scalar JSONs don't expand the pool while decoding, so the pool may be filled up to the end
+func TestDecodeAdditionalPoolEnd(t *testing.T) {
+	root, err := DecodeString(`{}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	for i := 0; i < StartNodePoolSize*4; i++ {
+		_, err := root.DecodeStringAdditional(`1`)
+		assert.NoError(t, err, "error while decoding")
+	}
+
+	root.AddField("a").MutateToJSON(root, `[1]`)
+	assert.Equal(t, `{"a":[1]}`, root.EncodeToString(), "wrong result json")
+}
+
 func TestDecodeManyObjects(t *testing.T) {
 	json := `{"no_key2":"100","somefield":{"no_key2":"100","somefield":{"no_key2":"100","somefield":{"no_key2":"100","somefield":{"no_key2":"100","somefield":{"no_key2":"100","somefield":{"no_key2":"100","somefield":"ok"},"no_key1":"100"},"no_key1":"100"},"no_key1":"100"},"no_key1":"100"},"no_key1":"100"},"no_key1":"100"}`
 	root, err := DecodeString(json)
@@ -243,6 +260,58 @@ func TestEncode(t *testing.T) {
 	assert.Equal(t, json, root.EncodeToString(), "wrong encoding")
 }
 
+func TestEncodeTo(t *testing.T) {
+	test := loadJSON("insane", [][]string{})
+
+	root, err := DecodeBytes(test.json)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	flushSize := EncodeFlushSize
+	EncodeFlushSize = 1024
+	defer func() { EncodeFlushSize = flushSize }()
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, root.EncodeTo(out), "error while encoding")
+	assert.Equal(t, root.EncodeToString(), out.String(), "wrong encoding")
+
+	for _, json := range []string{`{}`, `[]`, `"insane"`, `{"a":[1,{"b":{}}]}`} {
+		assert.NoError(t, root.DecodeString(json), "error while decoding")
+		out.Reset()
+		assert.NoError(t, root.EncodeTo(out), "error while encoding")
+		assert.Equal(t, json, out.String(), "wrong encoding")
+	}
+}
+
+type failingWriter struct {
+	limit int
+	err   error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		return w.limit, w.err
+	}
+	w.limit -= len(p)
+
+	return len(p), nil
+}
+
+func TestEncodeToErr(t *testing.T) {
+	test := loadJSON("insane", [][]string{})
+
+	root, err := DecodeBytes(test.json)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	writeErr := errors.New("connection reset")
+	err = root.EncodeTo(&failingWriter{limit: 100000, err: writeErr})
+	assert.Equal(t, writeErr, err, "write error should be returned")
+
+	err = root.EncodeTo(&failingWriter{limit: 100000})
+	assert.Equal(t, io.ErrShortWrite, err, "short write should be detected")
+}
+
 func TestString(t *testing.T) {
 	json := `["hello \\ \" op \\ \" op op","shit"]`
 
@@ -412,52 +481,6 @@ func TestInsertElement(t *testing.T) {
 	}
 }
 
-// it simply shouldn't crush
-func TestFuzz(t *testing.T) {
-	tests := []string{
-		`[]`,
-		`[0,1,2,3]`,
-		`[{},{},{},{}]`,
-		`[{"1":"1"},{"1":"1"},{"1":"1"},{"1":"1"}]`,
-		`[["1","1"],["1","1"],["1","1"],["1","1"]]`,
-		`[[],0]`,
-		`["a",{"6":"5","l":[3,4]},"c","d"]`,
-		`{}`,
-		`{"a":null}`,
-		`{"a":null,"b":null,"c":null}`,
-		`{"a":null,"b":null,"c":null,"d":null}`,
-		`{"a":"a"}`,
-		`{"a":"a","b":null,"c":null,"d":null}`,
-		`{"x":{"a":"a","e":"e"}}`,
-		`{"x":{"a":"a","e":"e"},"b":null,"c":null,"d":null}`,
-		`{"x":["a","a"]}`,
-		`{"x":["a","a"],"b":null,"c":null,"d":null}`,
-		`[null]`,
-		`[null,null,null]`,
-		`[null,null,null,null]`,
-		`["a",null,null,null]`,
-		`["a","a"]`,
-		`["a","a",null,null,null]`,
-		`[{"a":"a"}]`,
-		`[{"a":"a"},null,null,null]`,
-		`[["a","a"]]`,
-		`[["a","a"],null,null,null]`,
-		`[]`,
-		`[0,1,2,3]`,
-		`[{},{},{},{}]`,
-		`[{"1":"1"},{"1":"1"},{"1":"1"},{"1":"1"}]`,
-		`[["1","1"],["1","1"],["1","1"],["1","1"]]`,
-		`[[],0]`,
-		`["a",{"6":"5","l":[3,4]},"c","d"]`,
-	}
-
-	// hell yeah, we are deterministic
-	rand.Seed(666)
-	for _, test := range tests {
-		Fuzz([]byte(test))
-	}
-}
-
 func TestArraySuicide(t *testing.T) {
 	tests := []string{
 		`[]`,
@@ -1041,7 +1064,7 @@ func TestEscapeString(t *testing.T) {
 
 	out := make([]byte, 0, 0)
 	for _, test := range tests {
-		out = escapeString(out[:0], test.s)
+		out = escapeString(out[:0], test.s, nil)
 		assert.Equal(t, string(strconv.AppendQuote(nil, test.s)), string(out), "wrong escaping")
 	}
 }
@@ -0,0 +1,29 @@
+//go:build go1.23
+
+package insaneJSON
+
+import "iter"
+
+/*
+Fields returns iterator over object field names and values.
+It's safe to delete current field in the loop body using Suicide() of the value or the field,
+in this case the iterator continues with the field that took its place, so all fields are visited once.
+Fields added in the loop body are visited too. Deleting other fields leads to undefined order.
+*/
+func (n *Node) Fields() iter.Seq2[string, *Node] {
+	n.debugCheck()
+
+	return n.eachField
+}
+
+/*
+Elements returns iterator over array elements and their indexes.
+It's safe to delete current element in the loop body using Suicide(),
+indexes are counted from the start of iteration, so they don't change after deletions.
+Elements added in the loop body are visited too. Deleting other elements leads to undefined order.
+*/
+func (n *Node) Elements() iter.Seq2[int, *Node] {
+	n.debugCheck()
+
+	return n.eachElement
+}
@@ -0,0 +1,17 @@
+//go:build !go1.23
+
+package insaneJSON
+
+// Fields is a callback fallback for go versions without iter package, see iter.go
+func (n *Node) Fields() func(yield func(string, *Node) bool) {
+	n.debugCheck()
+
+	return n.eachField
+}
+
+// Elements is a callback fallback for go versions without iter package, see iter.go
+func (n *Node) Elements() func(yield func(int, *Node) bool) {
+	n.debugCheck()
+
+	return n.eachElement
+}
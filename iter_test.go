@@ -0,0 +1,55 @@
+//go:build go1.23
+
+package insaneJSON
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFields(t *testing.T) {
+	root, err := DecodeString(`{"a":1,"b":2,"c":3,"d":4,"e":5}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	names := make([]string, 0)
+	for name, value := range root.Fields() {
+		names = append(names, name)
+		if value.AsInt()%2 == 0 {
+			value.Suicide()
+		}
+	}
+
+	assert.Equal(t, []string{"a", "b", "e", "c", "d"}, names, "all fields should be visited once")
+	assert.Equal(t, `{"a":1,"e":5,"c":3}`, root.EncodeToString(), "wrong result json")
+
+	for name := range root.Fields() {
+		if name == "e" {
+			break
+		}
+		names = append(names, name)
+	}
+	assert.Equal(t, "a", names[len(names)-1], "iteration should be stopped")
+}
+
+func TestElements(t *testing.T) {
+	root, err := DecodeString(`[1,2,2,3,4]`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	indexes := make([]int, 0)
+	for index, element := range root.Elements() {
+		indexes = append(indexes, index)
+		if element.AsInt()%2 == 0 {
+			element.Suicide()
+		}
+	}
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, indexes, "all elements should be visited once")
+	assert.Equal(t, `[1,3]`, root.EncodeToString(), "wrong result json")
+
+	for range root.Dig("missing").Elements() {
+		assert.Fail(t, "nil node has no elements")
+	}
+}
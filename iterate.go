@@ -0,0 +1,45 @@
+package insaneJSON
+
+// eachField calls yield for every object field, see Fields()
+func (n *Node) eachField(yield func(string, *Node) bool) {
+	if n == nil || n.bits&hellBitObject != hellBitObject {
+		return
+	}
+
+	for i := 0; i < len(n.nodes); {
+		field := n.nodes[i]
+		if field.bits&hellBitEscapedField == hellBitEscapedField {
+			field.unescapeField()
+		}
+
+		value := field.next
+		if !yield(field.data, value) {
+			return
+		}
+
+		// field is deleted, so the last field took its place
+		if i < len(n.nodes) && n.nodes[i] == field {
+			i++
+		}
+	}
+}
+
+// eachElement calls yield for every array element, see Elements()
+func (n *Node) eachElement(yield func(int, *Node) bool) {
+	if n == nil || n.bits&hellBitArray != hellBitArray {
+		return
+	}
+
+	index := 0
+	for i := 0; i < len(n.nodes); index++ {
+		element := n.nodes[i]
+		if !yield(index, element) {
+			return
+		}
+
+		// element is deleted, so the next element took its place
+		if i < len(n.nodes) && n.nodes[i] == element {
+			i++
+		}
+	}
+}
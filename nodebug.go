@@ -0,0 +1,26 @@
+//go:build !insanedebug
+
+package insaneJSON
+
+// DebugMode is true if the package is built with insanedebug tag, checkout debug.go
+const DebugMode = false
+
+type nodeDebug struct{}
+
+type decoderDebug struct{}
+
+func (d *decoder) debugReset() {}
+
+func (d *decoder) debugStamp(from, to int) {}
+
+func (d *decoder) debugStampNode(node *Node) {}
+
+func (d *decoder) debugRelease() {}
+
+func (d *decoder) debugRetire() {}
+
+func (r *Root) debugCheck() {}
+
+func (n *Node) debugCheck() {}
+
+func (n *StrictNode) debugCheck() {}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package insaneJSON
+
+const raceEnabled = false
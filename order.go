@@ -0,0 +1,154 @@
+package insaneJSON
+
+// SortFields sorts object fields by names in byte-wise order, values are kept
+func (n *Node) SortFields() *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitObject != hellBitObject {
+		return nil
+	}
+
+	if len(n.nodes) < 2 {
+		return n
+	}
+
+	for _, field := range n.nodes {
+		if field.bits&hellBitEscapedField == hellBitEscapedField {
+			field.unescapeField()
+		}
+	}
+
+	end := n.nodes[len(n.nodes)-1].next.next
+	stableSort(n.nodes, fieldNameLess)
+
+	n.bits += hellBitsDirtyStep
+	n.bits &^= hellBitUseFoldMap
+	n.relinkFields(end)
+	n.reindexFields(0, len(n.nodes))
+
+	return n
+}
+
+// MoveFieldBefore moves field with provided name right before other field
+// returns nil if object doesn't have one of the fields
+func (n *Node) MoveFieldBefore(name string, other string) *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitObject != hellBitObject {
+		return nil
+	}
+
+	from := n.fieldIndex(name)
+	to := n.fieldIndex(other)
+	if from == -1 || to == -1 {
+		return nil
+	}
+
+	if from < to {
+		to--
+	}
+	n.moveField(from, to)
+
+	return n
+}
+
+/*
+InsertFieldAt adds new field at provided position and returns its null value like AddField() does.
+If object already has the field, its value is returned and position isn't changed.
+Returns nil if position is out of range.
+*/
+func (n *Node) InsertFieldAt(pos int, name string) *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitObject != hellBitObject || pos < 0 || pos > len(n.nodes) {
+		return nil
+	}
+
+	if value := n.Dig(name); value != nil {
+		return value
+	}
+
+	value := n.AddField(name)
+	n.moveField(len(n.nodes)-1, pos)
+
+	return value
+}
+
+// moveField moves field from one index to another shifting fields between them
+func (n *Node) moveField(from, to int) {
+	if from == to {
+		return
+	}
+
+	end := n.nodes[len(n.nodes)-1].next.next
+	field := n.nodes[from]
+	if from < to {
+		copy(n.nodes[from:to], n.nodes[from+1:to+1])
+		n.nodes[to] = field
+		n.reindexFields(from, to+1)
+	} else {
+		copy(n.nodes[to+1:from+1], n.nodes[to:from])
+		n.nodes[to] = field
+		n.reindexFields(to, from+1)
+	}
+
+	n.bits += hellBitsDirtyStep
+	n.bits &^= hellBitUseFoldMap
+	n.relinkFields(end)
+}
+
+// DeleteFieldOrdered deletes object field keeping order of other fields, checkout SuicideOrdered()
+// returns nil if node isn't an object
+func (n *Node) DeleteFieldOrdered(name string) *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitObject != hellBitObject {
+		return nil
+	}
+
+	n.Dig(name).suicide(true)
+
+	return n
+}
+
+// deleteFieldOrdered deletes field keeping order of other fields, owner should be marked as dirty
+func (n *Node) deleteFieldOrdered(index int) {
+	l := len(n.nodes)
+	field := n.nodes[index]
+	if index > 0 {
+		n.nodes[index-1].next.next = field.next.next
+	}
+
+	copy(n.nodes[index:], n.nodes[index+1:])
+	n.nodes[l-1] = nil
+	n.nodes = n.nodes[:l-1]
+
+	if n.bits&hellBitUseMap == hellBitUseMap {
+		delete(n.maps.index, field.data)
+	}
+	n.reindexFields(index, l-1)
+}
+
+// reindexFields updates fields map for fields from i to j, j isn't included
+func (n *Node) reindexFields(i, j int) {
+	if n.bits&hellBitUseMap != hellBitUseMap {
+		return
+	}
+
+	for ; i < j; i++ {
+		n.maps.index[n.nodes[i].data] = i
+	}
+}
+
+func (n *Node) fieldIndex(name string) int {
+	value := n.Dig(name)
+	if value == nil {
+		return -1
+	}
+
+	return value.actualizeIndex()
+}
+
+func fieldNameLess(a, b *Node) bool {
+	return a.data < b.data
+}
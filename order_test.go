@@ -0,0 +1,126 @@
+package insaneJSON
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuicideOrdered(t *testing.T) {
+	root, err := DecodeString(`{"a":1,"b":2,"c":3,"d":4}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	d := root.Dig("d")
+	root.Dig("b").SuicideOrdered()
+	assert.Equal(t, `{"a":1,"c":3,"d":4}`, root.EncodeToString(), "order should be kept")
+
+	root.Dig("a").SuicideOrdered()
+	d.SuicideOrdered()
+	assert.Equal(t, `{"c":3}`, root.EncodeToString(), "order should be kept")
+
+	root.AddField("e").MutateToInt(5)
+	root.Dig("c").SuicideOrdered()
+	assert.Equal(t, `{"e":5}`, root.EncodeToString(), "wrong result json")
+}
+
+func TestDeleteFieldOrdered(t *testing.T) {
+	root, err := DecodeString(`{"a":1,"b":2,"c":3,"d":[4]}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.DeleteFieldOrdered("a").DeleteFieldOrdered("missing")
+	assert.Equal(t, `{"b":2,"c":3,"d":[4]}`, root.EncodeToString(), "order should be kept")
+
+	root.Dig("b").Suicide()
+	assert.Equal(t, `{"d":[4],"c":3}`, root.EncodeToString(), "Suicide() shouldn't keep order")
+
+	assert.Nil(t, root.Dig("d").DeleteFieldOrdered("0"), "array should fail")
+	assert.Nil(t, root.Dig("missing").DeleteFieldOrdered("a"), "missing node should fail")
+}
+
+func TestSuicideOrderedMap(t *testing.T) {
+	fields := make([]string, 0)
+	for i := 0; i < MapUseThreshold*2; i++ {
+		fields = append(fields, `"f`+strconv.Itoa(i)+`":`+strconv.Itoa(i))
+	}
+	root, err := DecodeString(`{` + strings.Join(fields, ",") + `}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.Dig("f0").SuicideOrdered()
+	root.DeleteFieldOrdered("f5")
+	assert.Equal(t, `{`+strings.Join(append(append([]string{}, fields[1:5]...), fields[6:]...), ",")+`}`, root.EncodeToString(), "order should be kept")
+
+	for i := 1; i < MapUseThreshold*2; i++ {
+		if i == 5 {
+			assert.Nil(t, root.Dig("f5"), "field should be deleted")
+			continue
+		}
+		assert.Equal(t, i, root.Dig("f"+strconv.Itoa(i)).AsInt(), "wrong field value")
+	}
+}
+
+func TestSortFields(t *testing.T) {
+	root, err := DecodeString(`{"c":{"z":1,"y":2},"a\u0062":1,"a":[2],"b":3}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.SortFields()
+	assert.Equal(t, `{"a":[2],"ab":1,"b":3,"c":{"z":1,"y":2}}`, root.EncodeToString(), "wrong result json")
+	assert.Equal(t, 3, root.Dig("b").AsInt(), "wrong result")
+
+	root.Dig("a").Suicide()
+	assert.Equal(t, `{"c":{"z":1,"y":2},"ab":1,"b":3}`, root.EncodeToString(), "wrong result json")
+
+	assert.Nil(t, root.Dig("c", "z").SortFields(), "not an object should fail")
+}
+
+func TestMoveFieldBefore(t *testing.T) {
+	root, err := DecodeString(`{"a":1,"b":2,"c":3,"d":4}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.MoveFieldBefore("d", "b")
+	assert.Equal(t, `{"a":1,"d":4,"b":2,"c":3}`, root.EncodeToString(), "wrong result json")
+
+	root.MoveFieldBefore("a", "c")
+	assert.Equal(t, `{"d":4,"b":2,"a":1,"c":3}`, root.EncodeToString(), "wrong result json")
+
+	root.MoveFieldBefore("a", "a")
+	root.MoveFieldBefore("b", "a")
+	assert.Equal(t, `{"d":4,"b":2,"a":1,"c":3}`, root.EncodeToString(), "moving to the same place shouldn't change json")
+
+	assert.Nil(t, root.MoveFieldBefore("x", "a"), "unknown field should fail")
+	assert.Nil(t, root.MoveFieldBefore("a", "x"), "unknown field should fail")
+
+	root.Dig("d").Suicide()
+	assert.Equal(t, `{"c":3,"b":2,"a":1}`, root.EncodeToString(), "wrong result json")
+	root.AddField("e").MutateToInt(5)
+	assert.Equal(t, `{"c":3,"b":2,"a":1,"e":5}`, root.EncodeToString(), "wrong result json")
+}
+
+func TestInsertFieldAt(t *testing.T) {
+	root, err := DecodeString(`{"a":1,"b":2}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.InsertFieldAt(0, "x").MutateToInt(0)
+	root.InsertFieldAt(2, "y").MutateToInt(3)
+	root.InsertFieldAt(4, "z").MutateToInt(4)
+	assert.Equal(t, `{"x":0,"a":1,"y":3,"b":2,"z":4}`, root.EncodeToString(), "wrong result json")
+
+	root.InsertFieldAt(0, "b").MutateToInt(5)
+	assert.Equal(t, `{"x":0,"a":1,"y":3,"b":5,"z":4}`, root.EncodeToString(), "existing field shouldn't be moved")
+
+	assert.Nil(t, root.InsertFieldAt(6, "w"), "wrong position should fail")
+	assert.Nil(t, root.InsertFieldAt(-1, "w"), "wrong position should fail")
+
+	empty, err := DecodeString(`{}`)
+	defer Release(empty)
+	assert.NoError(t, err, "error while decoding")
+	empty.InsertFieldAt(0, "a").MutateToInt(1)
+	assert.Equal(t, `{"a":1}`, empty.EncodeToString(), "wrong result json")
+}
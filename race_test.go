@@ -0,0 +1,6 @@
+//go:build race
+
+package insaneJSON
+
+// raceEnabled is true if tests are run with -race, sync.Pool drops items randomly then
+const raceEnabled = true
@@ -0,0 +1,216 @@
+package insaneJSON
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"hash"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+)
+
+// RedactAction tells Redactor what to do with a matched node
+type RedactAction int
+
+const (
+	// RedactDrop deletes the node
+	RedactDrop RedactAction = iota
+	// RedactReplace replaces the value with RedactRule.Replacement
+	RedactReplace
+	// RedactHash replaces the value with hex encoded HMAC-SHA256 of it, so equal values stay equal
+	RedactHash
+	// RedactMask replaces the value with "****" followed by RedactRule.KeepLast last characters
+	RedactMask
+)
+
+const redactMask = "****"
+
+/*
+RedactRule describes nodes to redact, a node should match both path and value if they are set.
+Path elements are field names or array indexes, "*" matches any single element and "**" matches any number of them.
+Value is matched against strings and numbers, other nodes match only by path.
+Objects and arrays are hashed and masked as encoded JSON.
+*/
+type RedactRule struct {
+	Path        []string
+	Value       *regexp.Regexp
+	Action      RedactAction
+	Replacement string
+	KeepLast    int
+}
+
+/*
+Redactor scrubs sensitive data from decoded JSON, it's safe for concurrent use.
+Rules are checked in order and the first matching one is applied.
+Results are placed into the root buffer, so redacting doesn't allocate memory.
+*/
+type Redactor struct {
+	rules  []RedactRule
+	states sync.Pool
+}
+
+type redactState struct {
+	mac   hash.Hash
+	root  *Root
+	rules []RedactRule
+	buf   []byte
+	fn    WalkFunc
+}
+
+// NewRedactor creates redactor, key is used by RedactHash action
+func NewRedactor(key []byte, rules ...RedactRule) (*Redactor, error) {
+	for _, rule := range rules {
+		if len(rule.Path) == 0 && rule.Value == nil {
+			return nil, ErrRedactWrongRule
+		}
+
+		switch rule.Action {
+		case RedactDrop, RedactReplace:
+		case RedactHash:
+			if len(key) == 0 {
+				return nil, ErrRedactKeyRequired
+			}
+		case RedactMask:
+			if rule.KeepLast < 0 {
+				return nil, ErrRedactWrongRule
+			}
+		default:
+			return nil, ErrRedactWrongAction
+		}
+	}
+
+	r := &Redactor{rules: append([]RedactRule(nil), rules...)}
+	key = append([]byte(nil), key...)
+	r.states.New = func() interface{} {
+		state := &redactState{
+			mac:   hmac.New(sha256.New, key),
+			rules: r.rules,
+			buf:   make([]byte, 0, 256),
+		}
+		state.fn = state.visit
+		return state
+	}
+
+	return r, nil
+}
+
+// Redact applies rules to the root in one walk
+func (r *Redactor) Redact(root *Root) {
+	if r == nil || root == nil || len(r.rules) == 0 {
+		return
+	}
+
+	state := r.states.Get().(*redactState)
+	state.root = root
+	root.Walk(state.fn)
+	state.root = nil
+	r.states.Put(state)
+}
+
+func (s *redactState) visit(path []string, node *Node) WalkAction {
+	for i := range s.rules {
+		rule := &s.rules[i]
+		if !s.match(rule, path, node) {
+			continue
+		}
+
+		s.apply(rule, node)
+		return WalkSkipChildren
+	}
+
+	return WalkContinue
+}
+
+func (s *redactState) match(rule *RedactRule, path []string, node *Node) bool {
+	if len(rule.Path) != 0 && !matchRedactPath(rule.Path, path) {
+		return false
+	}
+
+	if rule.Value != nil {
+		if node.bits&(hellBitString|hellBitEscapedString|hellBitNumber) == 0 {
+			return false
+		}
+		return rule.Value.MatchString(node.AsString())
+	}
+
+	return true
+}
+
+func (s *redactState) apply(rule *RedactRule, node *Node) {
+	switch rule.Action {
+	case RedactDrop:
+		// root can't be deleted, so it's emptied
+		if node.parent == nil {
+			node.MutateToObject()
+			return
+		}
+		node.Suicide()
+	case RedactReplace:
+		node.MutateToString(rule.Replacement)
+	case RedactHash:
+		s.buf = s.value(s.buf[:0], node)
+		s.mac.Reset()
+		_, _ = s.mac.Write(s.buf)
+		sum := s.mac.Sum(s.buf[:0])
+
+		buf := s.root.decoder.buf
+		l := len(buf)
+		for _, b := range sum {
+			buf = append(buf, hex[b>>4], hex[b&0xF])
+		}
+		s.root.decoder.buf = buf
+		node.MutateToString(toString(buf[l:]))
+	case RedactMask:
+		s.buf = s.value(s.buf[:0], node)
+		tail := len(s.buf)
+		for i := 0; i < rule.KeepLast && tail > 0; i++ {
+			_, size := utf8.DecodeLastRune(s.buf[:tail])
+			tail -= size
+		}
+		if tail == 0 {
+			// whole value is hidden if it's too short
+			tail = len(s.buf)
+		}
+
+		buf := s.root.decoder.buf
+		l := len(buf)
+		buf = append(buf, redactMask...)
+		buf = append(buf, s.buf[tail:]...)
+		s.root.decoder.buf = buf
+		node.MutateToString(toString(buf[l:]))
+	}
+}
+
+// value appends string value or encoded JSON of the node
+func (s *redactState) value(dst []byte, node *Node) []byte {
+	if node.bits&(hellBitString|hellBitEscapedString) != 0 {
+		return append(dst, node.AsString()...)
+	}
+
+	return node.Encode(dst)
+}
+
+func matchRedactPath(pattern []string, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(path); i++ {
+				if matchRedactPath(pattern, path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(path) == 0 || (pattern[0] != "*" && pattern[0] != path[0]) {
+			return false
+		}
+		pattern = pattern[1:]
+		path = path[1:]
+	}
+
+	return len(path) == 0
+}
@@ -0,0 +1,115 @@
+package insaneJSON
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	hexenc "encoding/hex"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	r, err := NewRedactor([]byte("secret"),
+		RedactRule{Path: []string{"password"}, Action: RedactDrop},
+		RedactRule{Path: []string{"users", "*", "token"}, Action: RedactReplace, Replacement: "[hidden]"},
+		RedactRule{Path: []string{"**", "email"}, Action: RedactHash},
+		RedactRule{Value: regexp.MustCompile(`^\d{16}$`), Action: RedactMask, KeepLast: 4},
+		RedactRule{Path: []string{"session"}, Action: RedactMask},
+	)
+	assert.NoError(t, err, "error while creating redactor")
+
+	root, err := DecodeString(`{"password":"p","users":[{"token":"t","email":"a@b.c"},{"token":{"x":1}}],"card":"4111111111111111","num":4111111111111111,"session":{"id":1},"meta":{"email":"a@b.c"}}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("a@b.c"))
+	hash := hexenc.EncodeToString(mac.Sum(nil))
+
+	r.Redact(root)
+	expected := `{"meta":{"email":"` + hash + `"},"users":[{"token":"[hidden]","email":"` + hash + `"},{"token":"[hidden]"}],"card":"****1111","num":"****1111","session":"****"}`
+	assert.Equal(t, expected, root.EncodeToString(), "wrong result json")
+}
+
+func TestRedactValues(t *testing.T) {
+	r, err := NewRedactor(nil,
+		RedactRule{Value: regexp.MustCompile(`@`), Action: RedactDrop},
+		RedactRule{Path: []string{"**"}, Value: regexp.MustCompile(`secret`), Action: RedactMask, KeepLast: 2},
+	)
+	assert.NoError(t, err, "error while creating redactor")
+
+	root, err := DecodeString(`{"a":["x@y","z",{"b":"my secret éé"}],"c":"x@y","d":"ok"}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	r.Redact(root)
+	assert.Equal(t, `{"a":["z",{"b":"****éé"}],"d":"ok"}`, root.EncodeToString(), "wrong result json")
+}
+
+func TestRedactRoot(t *testing.T) {
+	r, err := NewRedactor(nil, RedactRule{Path: []string{"**"}, Action: RedactDrop})
+	assert.NoError(t, err, "error while creating redactor")
+
+	root, err := DecodeString(`{"a":1}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	r.Redact(root)
+	assert.Equal(t, `{}`, root.EncodeToString(), "wrong result json")
+}
+
+func TestRedactErrors(t *testing.T) {
+	_, err := NewRedactor(nil, RedactRule{Path: []string{"a"}, Action: RedactHash})
+	assert.Equal(t, ErrRedactKeyRequired, err, "wrong error")
+
+	_, err = NewRedactor(nil, RedactRule{Action: RedactDrop})
+	assert.Equal(t, ErrRedactWrongRule, err, "wrong error")
+
+	_, err = NewRedactor(nil, RedactRule{Path: []string{"a"}, Action: RedactMask, KeepLast: -1})
+	assert.Equal(t, ErrRedactWrongRule, err, "wrong error")
+
+	_, err = NewRedactor(nil, RedactRule{Path: []string{"a"}, Action: RedactAction(100)})
+	assert.Equal(t, ErrRedactWrongAction, err, "wrong error")
+}
+
+func TestMatchRedactPath(t *testing.T) {
+	assert.True(t, matchRedactPath([]string{"a", "*", "c"}, []string{"a", "b", "c"}), "path should match")
+	assert.True(t, matchRedactPath([]string{"**", "c"}, []string{"a", "b", "c"}), "path should match")
+	assert.True(t, matchRedactPath([]string{"**", "c"}, []string{"c"}), "path should match")
+	assert.True(t, matchRedactPath([]string{"a", "**"}, []string{"a", "b", "c"}), "path should match")
+	assert.True(t, matchRedactPath([]string{"a", "**", "b", "**", "d"}, []string{"a", "b", "c", "d"}), "path should match")
+	assert.False(t, matchRedactPath([]string{"a", "*"}, []string{"a", "b", "c"}), "path shouldn't match")
+	assert.False(t, matchRedactPath([]string{"**", "b"}, []string{"a", "b", "c"}), "path shouldn't match")
+	assert.False(t, matchRedactPath([]string{"a"}, []string{}), "path shouldn't match")
+}
+
+func TestRedactNoAlloc(t *testing.T) {
+	if DebugMode {
+		t.Skip("debug mode doesn't reuse memory")
+	}
+	if raceEnabled {
+		t.Skip("sync.Pool doesn't keep items with -race")
+	}
+
+	r, err := NewRedactor([]byte("secret"),
+		RedactRule{Path: []string{"token"}, Action: RedactReplace, Replacement: "x"},
+		RedactRule{Path: []string{"**", "email"}, Action: RedactHash},
+		RedactRule{Value: regexp.MustCompile(`^\d{16}$`), Action: RedactMask, KeepLast: 4},
+	)
+	assert.NoError(t, err, "error while creating redactor")
+
+	root, err := DecodeString(`{}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	json := `{"token":"t","user":{"email":"a@b.c","card":"4111111111111111"},"list":[1,2,3]}`
+	_ = root.DecodeString(json)
+	r.Redact(root)
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = root.DecodeString(json)
+		r.Redact(root)
+	})
+	assert.Equal(t, float64(0), allocs, "redact shouldn't allocate")
+}
@@ -0,0 +1,174 @@
+package schema
+
+import (
+	"strings"
+
+	insaneJSON "github.com/ozontech/insane-json"
+)
+
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+/*
+Inferrer builds JSON Schema from sample documents, use it to process streams:
+samples are merged one by one, so they can be released right after Add().
+Types observed at the same path are merged into a union, numbers are "integer" until a fraction is observed.
+Fields present in every object of the path are required, array item schemas are merged into one.
+Inferrer isn't safe for concurrent use.
+*/
+type Inferrer struct {
+	root    inferred
+	samples int
+}
+
+type inferred struct {
+	types   int
+	objects int
+	fields  []*inferredField
+	index   map[string]int
+	items   *inferred
+}
+
+type inferredField struct {
+	name   string
+	count  int
+	schema *inferred
+}
+
+// InferSchema returns JSON Schema matching all samples, the result is detached and doesn't use pooled memory
+func InferSchema(samples ...*insaneJSON.Node) *insaneJSON.Node {
+	inferrer := &Inferrer{}
+	for _, sample := range samples {
+		inferrer.Add(sample)
+	}
+
+	return inferrer.Schema()
+}
+
+// Add merges the sample into the schema
+func (i *Inferrer) Add(sample *insaneJSON.Node) {
+	if sample.IsField() {
+		sample = sample.AsFieldValue()
+	}
+	if sample == nil {
+		return
+	}
+
+	i.samples++
+	i.root.add(sample)
+}
+
+// Samples returns count of added samples
+func (i *Inferrer) Samples() int {
+	return i.samples
+}
+
+// Schema returns JSON Schema of all added samples, the result is detached and doesn't use pooled memory
+func (i *Inferrer) Schema() *insaneJSON.Node {
+	root := insaneJSON.Spawn()
+	defer insaneJSON.Release(root)
+
+	root.MutateToObject()
+	root.AddFieldNoAlloc(root, "$schema").MutateToString(draft)
+	i.root.build(root, root.Node)
+
+	return root.Node.DeepCopy(nil)
+}
+
+func (s *inferred) add(node *insaneJSON.Node) {
+	switch {
+	case node.IsNull():
+		s.types |= typeNull
+	case node.IsTrue() || node.IsFalse():
+		s.types |= typeBoolean
+	case node.IsString():
+		s.types |= typeString
+	case node.IsNumber():
+		if isInteger(node) {
+			s.types |= typeInteger
+		} else {
+			s.types |= typeNumber
+		}
+	case node.IsObject():
+		s.types |= typeObject
+		s.objects++
+		for _, field := range node.AsFields() {
+			s.field(field.AsString()).add(field.AsFieldValue())
+		}
+	case node.IsArray():
+		s.types |= typeArray
+		for _, element := range node.AsArray() {
+			if s.items == nil {
+				s.items = &inferred{}
+			}
+			s.items.add(element)
+		}
+	}
+}
+
+// field returns schema of the field and counts it, fields are kept in order of appearance
+func (s *inferred) field(name string) *inferred {
+	if s.index == nil {
+		s.index = make(map[string]int)
+	}
+
+	index, has := s.index[name]
+	if !has {
+		index = len(s.fields)
+		s.index[strings.Clone(name)] = index
+		s.fields = append(s.fields, &inferredField{name: strings.Clone(name), schema: &inferred{}})
+	}
+
+	field := s.fields[index]
+	field.count++
+
+	return field.schema
+}
+
+func (s *inferred) build(root *insaneJSON.Root, node *insaneJSON.Node) {
+	types := s.types
+	if types&typeNumber != 0 {
+		// integers are numbers too
+		types &^= typeInteger
+	}
+
+	names := make([]string, 0, len(typeOrder))
+	for _, name := range typeOrder {
+		if types&typeNames[name] != 0 {
+			names = append(names, name)
+		}
+	}
+
+	switch len(names) {
+	case 0:
+		// nothing is observed, so anything is allowed
+	case 1:
+		node.AddFieldNoAlloc(root, "type").MutateToString(names[0])
+	default:
+		list := node.AddFieldNoAlloc(root, "type").MutateToArray()
+		for _, name := range names {
+			list.AddElementNoAlloc(root).MutateToString(name)
+		}
+	}
+
+	if len(s.fields) > 0 {
+		properties := node.AddFieldNoAlloc(root, "properties").MutateToObject()
+		for _, field := range s.fields {
+			field.schema.build(root, properties.AddFieldNoAlloc(root, field.name).MutateToObject())
+		}
+
+		var required *insaneJSON.Node
+		for _, field := range s.fields {
+			if field.count < s.objects {
+				continue
+			}
+			if required == nil {
+				required = node.AddFieldNoAlloc(root, "required").MutateToArray()
+			}
+			required.AddElementNoAlloc(root).MutateToString(field.name)
+		}
+	}
+
+	if s.items != nil {
+		s.items.build(root, node.AddFieldNoAlloc(root, "items").MutateToObject())
+	}
+}
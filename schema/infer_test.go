@@ -0,0 +1,67 @@
+package schema
+
+import (
+	"bufio"
+	"os"
+	"testing"
+
+	insaneJSON "github.com/ozontech/insane-json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInferSchema(t *testing.T) {
+	a, err := insaneJSON.DecodeString(`{"id":1,"name":"a","tags":["x"],"score":1,"meta":{"ok":true}}`)
+	defer insaneJSON.Release(a)
+	assert.NoError(t, err, "error while decoding")
+
+	b, err := insaneJSON.DecodeString(`{"id":2,"name":null,"tags":[1.5,"y"],"score":2.5,"extra":[]}`)
+	defer insaneJSON.Release(b)
+	assert.NoError(t, err, "error while decoding")
+
+	result := InferSchema(a.Node, b.Node)
+	expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object",` +
+		`"properties":{"id":{"type":"integer"},"name":{"type":["null","string"]},"tags":{"type":"array","items":{"type":["number","string"]}},` +
+		`"score":{"type":"number"},"meta":{"type":"object","properties":{"ok":{"type":"boolean"}},"required":["ok"]},"extra":{"type":"array"}},` +
+		`"required":["id","name","tags","score"]}`
+	assert.Equal(t, expected, result.EncodeToString(), "wrong result json")
+
+	s, err := Compile(result)
+	assert.NoError(t, err, "error while compiling schema")
+	assert.NoError(t, s.Validate(a.Node), "sample should match inferred schema")
+	assert.NoError(t, s.Validate(b.Node), "sample should match inferred schema")
+}
+
+func TestInferSchemaEmpty(t *testing.T) {
+	assert.Equal(t, `{"$schema":"https://json-schema.org/draft/2020-12/schema"}`, InferSchema().EncodeToString(), "wrong result json")
+}
+
+func TestInferrerStream(t *testing.T) {
+	file, err := os.Open("../benchdata/chaotic-workload.log")
+	assert.NoError(t, err, "error while opening file")
+	defer file.Close()
+
+	inferrer := &Inferrer{}
+	root := insaneJSON.Spawn()
+	defer insaneJSON.Release(root)
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		err := root.DecodeString(scanner.Text())
+		assert.NoError(t, err, "error while decoding")
+		inferrer.Add(root.Node)
+	}
+	assert.NoError(t, scanner.Err(), "error while reading file")
+	assert.Equal(t, len(lines), inferrer.Samples(), "wrong samples count")
+
+	s, err := Compile(inferrer.Schema())
+	assert.NoError(t, err, "error while compiling schema")
+
+	for _, line := range lines {
+		err := root.DecodeString(line)
+		assert.NoError(t, err, "error while decoding")
+		assert.NoError(t, s.Validate(root.Node), "sample should match inferred schema")
+	}
+}
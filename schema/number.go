@@ -0,0 +1,195 @@
+package schema
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxExponent saturates exponents of numbers, so parsing never overflows,
+// numbers which differ only in exponents above it are considered equal
+const maxExponent = 1 << 27
+
+/*
+decimal is an exact form of JSON number: 0.digits * 10^exp with sign.
+Digits have no leading and trailing zeros, zero has no digits and isn't negative.
+It keeps integer checks and comparisons cheap for numbers like 1e1000000,
+which are slow to convert to big.Rat, since only digits of the input are stored.
+*/
+type decimal struct {
+	neg    bool
+	digits string
+	exp    int
+}
+
+// literal is a number of a schema keyword, text is kept for error messages
+type literal struct {
+	value decimal
+	text  string
+}
+
+// parseDecimal returns false if s isn't a JSON number, leading zeros are accepted like decoder does
+func parseDecimal(s string) (decimal, bool) {
+	d := decimal{}
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		d.neg = true
+		i++
+	}
+
+	start := i
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == start {
+		return d, false
+	}
+	intPart := s[start:i]
+
+	fracPart := ""
+	if i < len(s) && s[i] == '.' {
+		i++
+		start = i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return d, false
+		}
+		fracPart = s[start:i]
+	}
+
+	exp := 0
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		negExp := false
+		if i < len(s) && (s[i] == '-' || s[i] == '+') {
+			negExp = s[i] == '-'
+			i++
+		}
+		start = i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			exp = exp*10 + int(s[i]-'0')
+			if exp > maxExponent {
+				exp = maxExponent
+			}
+			i++
+		}
+		if i == start {
+			return d, false
+		}
+		if negExp {
+			exp = -exp
+		}
+	}
+	if i != len(s) {
+		return d, false
+	}
+
+	digits := strings.TrimLeft(intPart, "0")
+	exp += len(digits)
+	if digits == "" {
+		trimmed := strings.TrimLeft(fracPart, "0")
+		exp -= len(fracPart) - len(trimmed)
+		digits = trimmed
+	} else {
+		digits += fracPart
+	}
+
+	d.digits = strings.TrimRight(digits, "0")
+	if d.digits == "" {
+		return decimal{}, true
+	}
+	d.exp = exp
+
+	return d, true
+}
+
+func (d decimal) isInteger() bool {
+	return d.digits == "" || d.exp >= len(d.digits)
+}
+
+// cmp returns -1 if d < o, 0 if d == o and 1 if d > o
+func (d decimal) cmp(o decimal) int {
+	if a, b := d.sign(), o.sign(); a != b || a == 0 {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	// magnitudes are compared, then result is reversed for negative numbers
+	result := 0
+	switch {
+	case d.exp != o.exp:
+		result = 1
+		if d.exp < o.exp {
+			result = -1
+		}
+	default:
+		result = strings.Compare(d.digits, o.digits)
+	}
+	if d.neg {
+		return -result
+	}
+
+	return result
+}
+
+func (d decimal) sign() int {
+	switch {
+	case d.digits == "":
+		return 0
+	case d.neg:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// int64 returns false if number isn't an integer or doesn't fit into int64
+func (d decimal) int64() (int64, bool) {
+	if !d.isInteger() || d.exp > 19 {
+		return 0, false
+	}
+
+	s := d.digits + strings.Repeat("0", d.exp-len(d.digits))
+	if d.neg {
+		s = "-" + s
+	}
+	x, err := strconv.ParseInt(s, 10, 64)
+
+	return x, err == nil
+}
+
+// isMultipleOf checks if d / m is an integer, m should be positive
+func (d decimal) isMultipleOf(m decimal) bool {
+	if d.digits == "" {
+		return true
+	}
+
+	v, _ := new(big.Int).SetString(d.digits, 10)
+	x, _ := new(big.Int).SetString(m.digits, 10)
+
+	// d / m = v / x * 10^k
+	k := (d.exp - len(d.digits)) - (m.exp - len(m.digits))
+	if k < 0 {
+		// v < 10^len(v), so it can't be divided by x * 10^-k if -k >= len(v)
+		if -k >= len(d.digits) {
+			return false
+		}
+		x.Mul(x, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-k)), nil))
+	} else {
+		// x = 2^p * 5^q * r, where p and q are less than 4 * len(x), so bigger powers of 10 don't change the result
+		if k > 4*len(m.digits) {
+			k = 4 * len(m.digits)
+		}
+		v.Mul(v, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(k)), nil))
+	}
+
+	return v.Mod(v, x).Sign() == 0
+}
@@ -0,0 +1,509 @@
+/*
+Package schema validates decoded JSON against JSON Schema draft 2020-12.
+
+Schema is compiled once and then used to validate any number of nodes:
+
+	s, err := schema.CompileString(`{"type":"object","required":["id"]}`)
+	root, err := insaneJSON.DecodeString(`{"name":"x"}`)
+	err = s.Validate(root.Node) // #: missing required property "id"
+
+Supported keywords: type, enum, const, properties, patternProperties, additionalProperties,
+required, dependentRequired, propertyNames, minProperties, maxProperties, prefixItems, items,
+contains, minContains, maxContains, minItems, maxItems, uniqueItems, minimum, maximum,
+exclusiveMinimum, exclusiveMaximum, multipleOf, minLength, maxLength, pattern, format,
+allOf, anyOf, oneOf, not, if, then, else, $ref, $defs and $anchor.
+Only local references are supported, regular expressions use Go syntax.
+*/
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	insaneJSON "github.com/ozontech/insane-json"
+)
+
+var ErrInvalidSchema = errors.New("invalid schema")
+
+const (
+	typeNull = 1 << iota
+	typeBoolean
+	typeObject
+	typeArray
+	typeNumber
+	typeString
+	typeInteger
+)
+
+var typeNames = map[string]int{
+	"null":    typeNull,
+	"boolean": typeBoolean,
+	"object":  typeObject,
+	"array":   typeArray,
+	"number":  typeNumber,
+	"string":  typeString,
+	"integer": typeInteger,
+}
+
+// typeOrder is used to print types in a stable order
+var typeOrder = []string{"null", "boolean", "object", "array", "number", "string", "integer"}
+
+// Schema is a compiled JSON Schema, it's immutable and safe for concurrent use
+type Schema struct {
+	isBool  bool
+	boolean bool
+
+	ref     string
+	refNode *Schema
+
+	types  int
+	enum   []*insaneJSON.Node
+	consts *insaneJSON.Node
+
+	properties        []property
+	patternProperties []patternProperty
+	additional        *Schema
+	required          []string
+	dependentRequired []dependency
+	propertyNames     *Schema
+	minProperties     int
+	maxProperties     int
+
+	prefixItems []*Schema
+	items       *Schema
+	contains    *Schema
+	minContains int
+	maxContains int
+	minItems    int
+	maxItems    int
+	uniqueItems bool
+
+	minimum          *literal
+	maximum          *literal
+	exclusiveMinimum *literal
+	exclusiveMaximum *literal
+	multipleOf       *literal
+
+	minLength int
+	maxLength int
+	pattern   *regexp.Regexp
+	format    string
+
+	allOf []*Schema
+	anyOf []*Schema
+	oneOf []*Schema
+	not   *Schema
+	cond  *Schema
+	then  *Schema
+	els   *Schema
+}
+
+type property struct {
+	name   string
+	schema *Schema
+}
+
+type patternProperty struct {
+	pattern *regexp.Regexp
+	schema  *Schema
+}
+
+type dependency struct {
+	name     string
+	required []string
+}
+
+type compiler struct {
+	root    *insaneJSON.Node
+	id      string
+	schemas map[string]*Schema
+	anchors map[string]string
+	refs    []*Schema
+}
+
+// CompileString decodes and compiles schema, the decoded JSON is released right after compilation
+func CompileString(json string) (*Schema, error) {
+	root, err := insaneJSON.DecodeString(json)
+	if err != nil {
+		return nil, err
+	}
+	defer insaneJSON.Release(root)
+
+	return Compile(root.Node)
+}
+
+// Compile compiles schema, the node isn't referenced by the result, so it can be released after that
+func Compile(node *insaneJSON.Node) (*Schema, error) {
+	c := &compiler{
+		root:    node,
+		schemas: make(map[string]*Schema),
+		anchors: make(map[string]string),
+	}
+	if id := node.Dig("$id"); id.IsString() {
+		c.id = strings.TrimSuffix(id.AsString(), "#")
+	}
+
+	s, err := c.compile(node, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// references may point to not compiled parts of the schema, so the list may grow
+	for i := 0; i < len(c.refs); i++ {
+		if err := c.resolve(c.refs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (c *compiler) resolve(s *Schema) error {
+	ref := s.ref
+	if c.id != "" && strings.HasPrefix(ref, c.id) {
+		ref = ref[len(c.id):]
+	}
+
+	if !strings.HasPrefix(ref, "#") {
+		return fmt.Errorf("%w: only local references are supported: %q", ErrInvalidSchema, s.ref)
+	}
+	ptr := ref[1:]
+
+	if ptr != "" && ptr[0] != '/' {
+		anchor, has := c.anchors[ptr]
+		if !has {
+			return fmt.Errorf("%w: unknown anchor: %q", ErrInvalidSchema, s.ref)
+		}
+		ptr = anchor
+	}
+
+	if target, has := c.schemas[ptr]; has {
+		s.refNode = target
+		return nil
+	}
+
+	node, err := c.pointer(ptr)
+	if err != nil {
+		return fmt.Errorf("%w: can't resolve reference %q: %s", ErrInvalidSchema, s.ref, err.Error())
+	}
+
+	target, err := c.compile(node, ptr)
+	if err != nil {
+		return err
+	}
+	s.refNode = target
+
+	return nil
+}
+
+// pointer finds node of the schema using JSON Pointer
+func (c *compiler) pointer(ptr string) (*insaneJSON.Node, error) {
+	node := c.root
+	if ptr == "" {
+		return node, nil
+	}
+
+	for _, token := range strings.Split(ptr[1:], "/") {
+		token = unescapeToken(token)
+		node = node.Dig(token)
+		if node == nil {
+			return nil, errors.New("node isn't found")
+		}
+	}
+
+	return node, nil
+}
+
+func (c *compiler) compile(node *insaneJSON.Node, ptr string) (*Schema, error) {
+	if s, has := c.schemas[ptr]; has {
+		return s, nil
+	}
+
+	s := &Schema{
+		minProperties: -1,
+		maxProperties: -1,
+		minContains:   -1,
+		maxContains:   -1,
+		minItems:      -1,
+		maxItems:      -1,
+		minLength:     -1,
+		maxLength:     -1,
+	}
+	c.schemas[ptr] = s
+
+	if node.IsTrue() || node.IsFalse() {
+		s.isBool = true
+		s.boolean = node.IsTrue()
+		return s, nil
+	}
+
+	if !node.IsObject() {
+		return nil, c.errorf(ptr, "schema should be an object or a boolean")
+	}
+
+	for _, field := range node.AsFields() {
+		if err := c.keyword(s, field.AsString(), field.AsFieldValue(), ptr); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (c *compiler) keyword(s *Schema, name string, value *insaneJSON.Node, ptr string) error {
+	var err error
+	keywordPtr := ptr + "/" + escapeToken(name)
+
+	switch name {
+	case "$ref":
+		if !value.IsString() {
+			return c.errorf(keywordPtr, "reference should be a string")
+		}
+		s.ref = strings.Clone(value.AsString())
+		c.refs = append(c.refs, s)
+	case "$anchor":
+		if !value.IsString() {
+			return c.errorf(keywordPtr, "anchor should be a string")
+		}
+		c.anchors[strings.Clone(value.AsString())] = ptr
+	case "$defs", "definitions":
+		_, err = c.schemaMap(value, keywordPtr)
+	case "type":
+		s.types, err = c.types(value, keywordPtr)
+	case "enum":
+		if !value.IsArray() {
+			return c.errorf(keywordPtr, "enum should be an array")
+		}
+		for _, element := range value.AsArray() {
+			s.enum = append(s.enum, element.DeepCopy(nil))
+		}
+	case "const":
+		s.consts = value.DeepCopy(nil)
+	case "properties":
+		var schemas map[string]*Schema
+		schemas, err = c.schemaMap(value, keywordPtr)
+		for _, field := range value.AsFields() {
+			name := field.AsString()
+			s.properties = append(s.properties, property{name: strings.Clone(name), schema: schemas[name]})
+		}
+	case "patternProperties":
+		if !value.IsObject() {
+			return c.errorf(keywordPtr, "patternProperties should be an object")
+		}
+		for _, field := range value.AsFields() {
+			fieldPtr := keywordPtr + "/" + escapeToken(field.AsString())
+			re, err := regexp.Compile(strings.Clone(field.AsString()))
+			if err != nil {
+				return c.errorf(fieldPtr, "wrong pattern: %s", err.Error())
+			}
+			sub, err := c.compile(field.AsFieldValue(), fieldPtr)
+			if err != nil {
+				return err
+			}
+			s.patternProperties = append(s.patternProperties, patternProperty{pattern: re, schema: sub})
+		}
+	case "additionalProperties":
+		s.additional, err = c.compile(value, keywordPtr)
+	case "required":
+		s.required, err = c.strings(value, keywordPtr)
+	case "dependentRequired":
+		if !value.IsObject() {
+			return c.errorf(keywordPtr, "dependentRequired should be an object")
+		}
+		for _, field := range value.AsFields() {
+			required, err := c.strings(field.AsFieldValue(), keywordPtr+"/"+escapeToken(field.AsString()))
+			if err != nil {
+				return err
+			}
+			s.dependentRequired = append(s.dependentRequired, dependency{name: strings.Clone(field.AsString()), required: required})
+		}
+	case "propertyNames":
+		s.propertyNames, err = c.compile(value, keywordPtr)
+	case "minProperties":
+		s.minProperties, err = c.count(value, keywordPtr)
+	case "maxProperties":
+		s.maxProperties, err = c.count(value, keywordPtr)
+	case "prefixItems":
+		s.prefixItems, err = c.schemaList(value, keywordPtr)
+	case "items":
+		s.items, err = c.compile(value, keywordPtr)
+	case "contains":
+		s.contains, err = c.compile(value, keywordPtr)
+	case "minContains":
+		s.minContains, err = c.count(value, keywordPtr)
+	case "maxContains":
+		s.maxContains, err = c.count(value, keywordPtr)
+	case "minItems":
+		s.minItems, err = c.count(value, keywordPtr)
+	case "maxItems":
+		s.maxItems, err = c.count(value, keywordPtr)
+	case "uniqueItems":
+		s.uniqueItems = value.IsTrue()
+	case "minimum":
+		s.minimum, err = c.number(value, keywordPtr)
+	case "maximum":
+		s.maximum, err = c.number(value, keywordPtr)
+	case "exclusiveMinimum":
+		s.exclusiveMinimum, err = c.number(value, keywordPtr)
+	case "exclusiveMaximum":
+		s.exclusiveMaximum, err = c.number(value, keywordPtr)
+	case "multipleOf":
+		s.multipleOf, err = c.number(value, keywordPtr)
+		if err != nil || s.multipleOf.value.sign() <= 0 {
+			return c.errorf(keywordPtr, "multipleOf should be a positive number")
+		}
+	case "minLength":
+		s.minLength, err = c.count(value, keywordPtr)
+	case "maxLength":
+		s.maxLength, err = c.count(value, keywordPtr)
+	case "pattern":
+		if !value.IsString() {
+			return c.errorf(keywordPtr, "pattern should be a string")
+		}
+		s.pattern, err = regexp.Compile(strings.Clone(value.AsString()))
+		if err != nil {
+			return c.errorf(keywordPtr, "wrong pattern: %s", err.Error())
+		}
+	case "format":
+		if !value.IsString() {
+			return c.errorf(keywordPtr, "format should be a string")
+		}
+		s.format = strings.Clone(value.AsString())
+	case "allOf":
+		s.allOf, err = c.schemaList(value, keywordPtr)
+	case "anyOf":
+		s.anyOf, err = c.schemaList(value, keywordPtr)
+	case "oneOf":
+		s.oneOf, err = c.schemaList(value, keywordPtr)
+	case "not":
+		s.not, err = c.compile(value, keywordPtr)
+	case "if":
+		s.cond, err = c.compile(value, keywordPtr)
+	case "then":
+		s.then, err = c.compile(value, keywordPtr)
+	case "else":
+		s.els, err = c.compile(value, keywordPtr)
+	}
+
+	return err
+}
+
+func (c *compiler) schemaList(node *insaneJSON.Node, ptr string) ([]*Schema, error) {
+	if !node.IsArray() || len(node.AsArray()) == 0 {
+		return nil, c.errorf(ptr, "should be a non empty array of schemas")
+	}
+
+	list := make([]*Schema, 0, len(node.AsArray()))
+	for i, element := range node.AsArray() {
+		s, err := c.compile(element, ptr+"/"+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+
+	return list, nil
+}
+
+func (c *compiler) schemaMap(node *insaneJSON.Node, ptr string) (map[string]*Schema, error) {
+	if !node.IsObject() {
+		return nil, c.errorf(ptr, "should be an object of schemas")
+	}
+
+	schemas := make(map[string]*Schema, len(node.AsFields()))
+	for _, field := range node.AsFields() {
+		s, err := c.compile(field.AsFieldValue(), ptr+"/"+escapeToken(field.AsString()))
+		if err != nil {
+			return nil, err
+		}
+		schemas[strings.Clone(field.AsString())] = s
+	}
+
+	return schemas, nil
+}
+
+func (c *compiler) types(node *insaneJSON.Node, ptr string) (int, error) {
+	if node.IsString() {
+		t, has := typeNames[node.AsString()]
+		if !has {
+			return 0, c.errorf(ptr, "unknown type %q", node.AsString())
+		}
+		return t, nil
+	}
+
+	names, err := c.strings(node, ptr)
+	if err != nil {
+		return 0, err
+	}
+
+	types := 0
+	for _, name := range names {
+		t, has := typeNames[name]
+		if !has {
+			return 0, c.errorf(ptr, "unknown type %q", name)
+		}
+		types |= t
+	}
+
+	return types, nil
+}
+
+func (c *compiler) strings(node *insaneJSON.Node, ptr string) ([]string, error) {
+	if !node.IsArray() {
+		return nil, c.errorf(ptr, "should be an array of strings")
+	}
+
+	list := make([]string, 0, len(node.AsArray()))
+	for _, element := range node.AsArray() {
+		if !element.IsString() {
+			return nil, c.errorf(ptr, "should be an array of strings")
+		}
+		list = append(list, strings.Clone(element.AsString()))
+	}
+
+	return list, nil
+}
+
+func (c *compiler) count(node *insaneJSON.Node, ptr string) (int, error) {
+	d, ok := parseDecimal(string(node.AsNumber()))
+	if !node.IsNumber() || !ok || d.neg {
+		return 0, c.errorf(ptr, "should be a non negative integer")
+	}
+	x, ok := d.int64()
+	if !ok {
+		return 0, c.errorf(ptr, "should be a non negative integer")
+	}
+
+	return int(x), nil
+}
+
+func (c *compiler) number(node *insaneJSON.Node, ptr string) (*literal, error) {
+	// digits of decimal point to the text, so it shouldn't point to the schema JSON
+	text := strings.Clone(node.AsString())
+	x, ok := parseDecimal(text)
+	if !node.IsNumber() || !ok {
+		return nil, c.errorf(ptr, "should be a number")
+	}
+
+	return &literal{value: x, text: text}, nil
+}
+
+func (c *compiler) errorf(ptr string, format string, args ...interface{}) error {
+	return fmt.Errorf("%w at #%s: %s", ErrInvalidSchema, ptr, fmt.Sprintf(format, args...))
+}
+
+var (
+	tokenEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	tokenUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+func escapeToken(token string) string {
+	return tokenEscaper.Replace(token)
+}
+
+func unescapeToken(token string) string {
+	return tokenUnescaper.Replace(token)
+}
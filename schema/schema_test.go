@@ -0,0 +1,321 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	insaneJSON "github.com/ozontech/insane-json"
+	"github.com/stretchr/testify/assert"
+)
+
+func validate(t *testing.T, schema string, json string) []string {
+	s, err := CompileString(schema)
+	assert.NoError(t, err, "error while compiling schema")
+
+	root, err := insaneJSON.DecodeString(json)
+	defer insaneJSON.Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	err = s.Validate(root.Node)
+	if err == nil {
+		return nil
+	}
+
+	result := make([]string, 0)
+	for _, e := range err.(Errors) {
+		result = append(result, e.Keyword+" "+e.Error())
+	}
+
+	return result
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		schema   string
+		json     string
+		expected []string
+	}{
+		{schema: `true`, json: `{"a":1}`},
+		{schema: `false`, json: `1`, expected: []string{`false #: value isn't allowed`}},
+		{schema: `{"type":"string"}`, json: `1`, expected: []string{`type #: expected string, got number`}},
+		{schema: `{"type":["string","null"]}`, json: `null`},
+		{schema: `{"type":"integer"}`, json: `1.0`},
+		{schema: `{"type":"integer"}`, json: `1.5`, expected: []string{`type #: expected integer, got number`}},
+		{schema: `{"enum":[1,"a",{"b":[true]}]}`, json: `{"b":[true]}`},
+		{schema: `{"enum":[1,"a"]}`, json: `"b"`, expected: []string{`enum #: value should be one of enum values`}},
+		{schema: `{"const":1}`, json: `1.00`},
+		{schema: `{"const":{"a":null}}`, json: `{"a":0}`, expected: []string{`const #: value should be equal to const`}},
+		{
+			schema: `{"type":"object","properties":{"a":{"type":"number"},"b/c":{"type":"string"}},"required":["a","d"],"additionalProperties":false}`,
+			json:   `{"a":"x","b/c":1,"e":1}`,
+			expected: []string{
+				`required #: missing required property "d"`,
+				`type #/a: expected number, got string`,
+				`type #/b~1c: expected string, got number`,
+				`additionalProperties #: additional property "e" isn't allowed`,
+			},
+		},
+		{
+			schema:   `{"patternProperties":{"^x-":{"type":"string"}},"additionalProperties":{"type":"number"}}`,
+			json:     `{"x-a":"1","b":2,"c":"3"}`,
+			expected: []string{`type #/c: expected number, got string`},
+		},
+		{
+			schema:   `{"propertyNames":{"maxLength":2},"minProperties":3}`,
+			json:     `{"ab":1,"abc":2}`,
+			expected: []string{`minProperties #: object should have at least 3 properties`, `propertyNames #: property name "abc": length should be <= 2`},
+		},
+		{
+			schema:   `{"dependentRequired":{"a":["b"]}}`,
+			json:     `{"a":1}`,
+			expected: []string{`dependentRequired #: property "b" is required by "a"`},
+		},
+		{
+			schema:   `{"prefixItems":[{"type":"string"}],"items":{"type":"number"},"maxItems":2,"uniqueItems":true}`,
+			json:     `["a",1,"b",1]`,
+			expected: []string{`maxItems #: array should have at most 2 items`, `type #/2: expected number, got string`, `uniqueItems #: items 1 and 3 are equal`},
+		},
+		{
+			schema:   `{"contains":{"type":"string"},"maxContains":1}`,
+			json:     `[1,"a","b"]`,
+			expected: []string{`maxContains #: array should contain at most 1 matching items`},
+		},
+		{
+			schema:   `{"contains":{"type":"string"}}`,
+			json:     `[1]`,
+			expected: []string{`contains #: array should contain at least 1 matching items`},
+		},
+		{
+			schema:   `{"minimum":1,"exclusiveMaximum":10,"multipleOf":0.5}`,
+			json:     `10.25`,
+			expected: []string{`exclusiveMaximum #: value should be < 10`, `multipleOf #: value should be a multiple of 0.5`},
+		},
+		{schema: `{"multipleOf":0.01}`, json: `19.99`},
+		{
+			schema:   `{"minLength":2,"pattern":"^a"}`,
+			json:     `"ё"`,
+			expected: []string{`minLength #: length should be >= 2`, `pattern #: value should match pattern "^a"`},
+		},
+		{
+			schema:   `{"anyOf":[{"type":"string"},{"minimum":5}]}`,
+			json:     `1`,
+			expected: []string{`anyOf #: value doesn't match any schema of anyOf`},
+		},
+		{
+			schema:   `{"oneOf":[{"type":"number"},{"minimum":5}]}`,
+			json:     `6`,
+			expected: []string{`oneOf #: value matches 2 schemas of oneOf, exactly one is expected`},
+		},
+		{
+			schema:   `{"allOf":[{"type":"number"},{"not":{"const":6}}]}`,
+			json:     `6`,
+			expected: []string{`not #: value shouldn't match the schema of not`},
+		},
+		{
+			schema:   `{"if":{"properties":{"a":{"const":1}}},"then":{"required":["b"]},"else":{"required":["c"]}}`,
+			json:     `{"a":2}`,
+			expected: []string{`required #: missing required property "c"`},
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, validate(t, test.schema, test.json), "wrong errors for schema %s and json %s", test.schema, test.json)
+	}
+}
+
+func TestValidateRef(t *testing.T) {
+	schema := `{
+		"$id": "https://example.com/tree",
+		"$defs": {
+			"node": {
+				"$anchor": "node",
+				"type": "object",
+				"properties": {
+					"value": {"type": "integer"},
+					"children": {"type": "array", "items": {"$ref": "#node"}}
+				}
+			}
+		},
+		"properties": {
+			"root": {"$ref": "https://example.com/tree#/$defs/node"},
+			"self": {"$ref": "#"}
+		}
+	}`
+
+	assert.Nil(t, validate(t, schema, `{"root":{"value":1,"children":[{"value":2},{"value":3,"children":[]}]},"self":{}}`))
+	assert.Equal(t,
+		[]string{`type #/root/children/1/value: expected integer, got string`, `type #/self/root: expected object, got number`},
+		validate(t, schema, `{"root":{"children":[{},{"value":"x"}]},"self":{"root":1}}`),
+	)
+}
+
+func TestValidateFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		valid  []string
+		wrong  []string
+	}{
+		{format: "date-time", valid: []string{"2024-01-02T03:04:05Z", "2024-01-02t03:04:05.123+03:00"}, wrong: []string{"2024-01-02 03:04:05", "2024-13-02T03:04:05Z"}},
+		{format: "date", valid: []string{"2024-02-29"}, wrong: []string{"2023-02-29", "24-01-01"}},
+		{format: "time", valid: []string{"03:04:05Z", "03:04:05.5+01:00"}, wrong: []string{"03:04", "25:00:00Z"}},
+		{format: "email", valid: []string{"a.b@example.com"}, wrong: []string{"a", "A <a@b.c>"}},
+		{format: "hostname", valid: []string{"example.com", "a-b.c"}, wrong: []string{"-a.com", "a..b", "a_b"}},
+		{format: "ipv4", valid: []string{"127.0.0.1"}, wrong: []string{"::1", "256.0.0.1"}},
+		{format: "ipv6", valid: []string{"::1", "2001:db8::1"}, wrong: []string{"127.0.0.1", "fe80::1%eth0"}},
+		{format: "uri", valid: []string{"https://example.com/a?b"}, wrong: []string{"/a/b", "%zz"}},
+		{format: "uri-reference", valid: []string{"/a/b", "#x"}, wrong: []string{"%zz"}},
+		{format: "uuid", valid: []string{"123e4567-e89b-12d3-a456-426614174000"}, wrong: []string{"123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g"}},
+		{format: "regex", valid: []string{"^a+$"}, wrong: []string{"("}},
+		{format: "json-pointer", valid: []string{"", "/a~0b/~1"}, wrong: []string{"a", "/~2"}},
+		{format: "unknown", valid: []string{"anything"}},
+	}
+
+	for _, test := range tests {
+		s, err := CompileString(`{"format":"` + test.format + `"}`)
+		assert.NoError(t, err, "error while compiling schema")
+
+		for _, value := range test.valid {
+			assert.True(t, isValidString(s, value), "%q should be a valid %s", value, test.format)
+		}
+		for _, value := range test.wrong {
+			assert.False(t, isValidString(s, value), "%q shouldn't be a valid %s", value, test.format)
+		}
+	}
+
+	assert.Nil(t, validate(t, `{"format":"email"}`, `1`), "format should be applied to strings only")
+}
+
+func isValidString(s *Schema, value string) bool {
+	root := insaneJSON.Spawn()
+	defer insaneJSON.Release(root)
+
+	return s.IsValid(root.MutateToString(value))
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		schema   string
+		expected string
+	}{
+		{schema: `1`, expected: `invalid schema at #: schema should be an object or a boolean`},
+		{schema: `{"type":"int"}`, expected: `invalid schema at #/type: unknown type "int"`},
+		{schema: `{"properties":{"a":{"minLength":-1}}}`, expected: `invalid schema at #/properties/a/minLength: should be a non negative integer`},
+		{schema: `{"maxItems":1.5}`, expected: `invalid schema at #/maxItems: should be a non negative integer`},
+		{schema: `{"pattern":"("}`, expected: "invalid schema at #/pattern: wrong pattern: error parsing regexp: missing closing ): `(`"},
+		{schema: `{"anyOf":[]}`, expected: `invalid schema at #/anyOf: should be a non empty array of schemas`},
+		{schema: `{"multipleOf":0}`, expected: `invalid schema at #/multipleOf: multipleOf should be a positive number`},
+		{schema: `{"$ref":"#/$defs/x"}`, expected: `invalid schema: can't resolve reference "#/$defs/x": node isn't found`},
+		{schema: `{"$ref":"#x"}`, expected: `invalid schema: unknown anchor: "#x"`},
+		{schema: `{"$ref":"other.json"}`, expected: `invalid schema: only local references are supported: "other.json"`},
+	}
+
+	for _, test := range tests {
+		_, err := CompileString(test.schema)
+		assert.True(t, errors.Is(err, ErrInvalidSchema), "wrong error for schema %s", test.schema)
+		if err != nil {
+			assert.Equal(t, test.expected, err.Error(), "wrong error for schema %s", test.schema)
+		}
+	}
+}
+
+func TestValidateRecursion(t *testing.T) {
+	assert.Equal(t, []string{`$ref #: schema recursion is too deep`}, validate(t, `{"$ref":"#"}`, `1`))
+}
+
+func TestValidateHugeExponent(t *testing.T) {
+	tests := []struct {
+		schema   string
+		json     string
+		expected []string
+	}{
+		{schema: `{"type":"integer"}`, json: `1e100000000`},
+		{schema: `{"type":"integer"}`, json: `1e-100000000`, expected: []string{`type #: expected integer, got number`}},
+		{schema: `{"type":"integer"}`, json: `1.5e999999999999999999`},
+		{schema: `{"type":"integer"}`, json: `0e-999999999999999999`},
+		{schema: `{"const":1e100000000}`, json: `10e99999999`},
+		{schema: `{"const":1e100000000}`, json: `1e99999999`, expected: []string{`const #: value should be equal to const`}},
+		{schema: `{"uniqueItems":true}`, json: `[1e-100000000,0.1e-99999999]`, expected: []string{`uniqueItems #: items 0 and 1 are equal`}},
+		{schema: `{"multipleOf":3}`, json: `3e100000000`},
+		{schema: `{"multipleOf":3}`, json: `1e100000000`, expected: []string{`multipleOf #: value should be a multiple of 3`}},
+		{schema: `{"multipleOf":0.4}`, json: `2e100000000`},
+		{schema: `{"multipleOf":1e-100000000}`, json: `0.3`},
+		{schema: `{"multipleOf":1e100000000}`, json: `5`, expected: []string{`multipleOf #: value should be a multiple of 1e100000000`}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, validate(t, test.schema, test.json), "wrong errors for %s and %s", test.schema, test.json)
+	}
+}
+
+func TestValidateBounds(t *testing.T) {
+	tests := []struct {
+		schema   string
+		json     string
+		expected []string
+	}{
+		{schema: `{"maximum":9007199254740992}`, json: `9007199254740992`},
+		{schema: `{"maximum":9007199254740992}`, json: `9007199254740993`, expected: []string{`maximum #: value should be <= 9007199254740992`}},
+		{schema: `{"minimum":9007199254740993}`, json: `9007199254740992`, expected: []string{`minimum #: value should be >= 9007199254740993`}},
+		{schema: `{"exclusiveMaximum":9007199254740993}`, json: `9007199254740992.5`},
+		{schema: `{"exclusiveMinimum":-0.1e1}`, json: `-1.0`, expected: []string{`exclusiveMinimum #: value should be > -0.1e1`}},
+		{schema: `{"exclusiveMinimum":-1}`, json: `-0.999999999999999999999`},
+		{schema: `{"minimum":0}`, json: `-0`},
+		{schema: `{"maximum":1e400}`, json: `1e399`},
+		{schema: `{"maximum":-1e400}`, json: `-1e399`, expected: []string{`maximum #: value should be <= -1e400`}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, validate(t, test.schema, test.json), "wrong errors for %s and %s", test.schema, test.json)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		s       string
+		decimal decimal
+		ok      bool
+	}{
+		{s: "0", ok: true},
+		{s: "-0.000e5", ok: true},
+		{s: "120", decimal: decimal{digits: "12", exp: 3}, ok: true},
+		{s: "-0.0120", decimal: decimal{neg: true, digits: "12", exp: -1}, ok: true},
+		{s: "001.5E+2", decimal: decimal{digits: "15", exp: 3}, ok: true},
+		{s: "1e999999999999999999", decimal: decimal{digits: "1", exp: maxExponent + 1}, ok: true},
+		{s: ""},
+		{s: "-"},
+		{s: "1."},
+		{s: ".5"},
+		{s: "1e"},
+		{s: "1e+"},
+		{s: "+1"},
+		{s: "1 "},
+	}
+
+	for _, test := range tests {
+		d, ok := parseDecimal(test.s)
+		assert.Equal(t, test.ok, ok, "wrong result for %q", test.s)
+		if ok {
+			assert.Equal(t, test.decimal, d, "wrong decimal for %q", test.s)
+		}
+	}
+}
+
+func TestCompileIndependence(t *testing.T) {
+	root, err := insaneJSON.DecodeString(`{"properties":{"a":{"enum":["x","y"]}},"required":["a"]}`)
+	assert.NoError(t, err, "error while decoding")
+
+	s, err := Compile(root.Node)
+	assert.NoError(t, err, "error while compiling schema")
+	insaneJSON.Release(root)
+
+	// reuse decoder memory of the schema
+	other, err := insaneJSON.DecodeString(`{"properties":{"b":{"enum":["z","w"]}},"required":["b"]}`)
+	defer insaneJSON.Release(other)
+	assert.NoError(t, err, "error while decoding")
+
+	node, err := insaneJSON.DecodeString(`{"a":"y"}`)
+	defer insaneJSON.Release(node)
+	assert.NoError(t, err, "error while decoding")
+
+	assert.NoError(t, s.Validate(node.Node), "schema shouldn't depend on released JSON")
+}
@@ -0,0 +1,496 @@
+package schema
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	insaneJSON "github.com/ozontech/insane-json"
+)
+
+// maxDepth limits schema nesting during validation, so recursive references can't loop forever
+const maxDepth = 1000
+
+// Error is a single validation failure, Path is a JSON Pointer to the failed node
+type Error struct {
+	Path    string
+	Keyword string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return "#" + e.Path + ": " + e.Message
+}
+
+// Errors is a list of all validation failures returned by Validate()
+type Errors []*Error
+
+func (e Errors) Error() string {
+	b := strings.Builder{}
+	for i, err := range e {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(err.Error())
+	}
+
+	return b.String()
+}
+
+type validator struct {
+	path   []string
+	errors Errors
+	depth  int
+	names  *insaneJSON.Root // scratch root for propertyNames, it's spawned once per Validate() call
+}
+
+// Validate checks the node against the schema, returns Errors if the node isn't valid
+func (s *Schema) Validate(node *insaneJSON.Node) error {
+	if node.IsField() {
+		node = node.AsFieldValue()
+	}
+
+	v := &validator{}
+	v.validate(s, node)
+	if v.names != nil {
+		insaneJSON.Release(v.names)
+	}
+	if len(v.errors) == 0 {
+		return nil
+	}
+
+	return v.errors
+}
+
+// IsValid is the same as Validate(), but doesn't collect errors
+func (s *Schema) IsValid(node *insaneJSON.Node) bool {
+	return s.Validate(node) == nil
+}
+
+func (v *validator) validate(s *Schema, node *insaneJSON.Node) {
+	if s.isBool {
+		if !s.boolean {
+			v.fail("false", "value isn't allowed")
+		}
+		return
+	}
+
+	if v.depth == maxDepth {
+		v.fail("$ref", "schema recursion is too deep")
+		return
+	}
+
+	if s.refNode != nil {
+		v.depth++
+		v.validate(s.refNode, node)
+		v.depth--
+	}
+
+	if s.types != 0 && !matchType(s.types, node) {
+		v.fail("type", "expected "+typesString(s.types)+", got "+nodeType(node))
+	}
+
+	if s.enum != nil {
+		found := false
+		for _, value := range s.enum {
+			if equal(value, node) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail("enum", "value should be one of enum values")
+		}
+	}
+
+	if s.consts != nil && !equal(s.consts, node) {
+		v.fail("const", "value should be equal to const")
+	}
+
+	switch {
+	case node.IsObject():
+		v.validateObject(s, node)
+	case node.IsArray():
+		v.validateArray(s, node)
+	case node.IsNumber():
+		v.validateNumber(s, node)
+	case node.IsString():
+		v.validateString(s, node)
+	}
+
+	v.validateCombinators(s, node)
+}
+
+func (v *validator) validateObject(s *Schema, node *insaneJSON.Node) {
+	fields := node.AsFields()
+
+	if s.minProperties != -1 && len(fields) < s.minProperties {
+		v.fail("minProperties", "object should have at least "+strconv.Itoa(s.minProperties)+" properties")
+	}
+	if s.maxProperties != -1 && len(fields) > s.maxProperties {
+		v.fail("maxProperties", "object should have at most "+strconv.Itoa(s.maxProperties)+" properties")
+	}
+
+	for _, name := range s.required {
+		if node.Dig(name) == nil {
+			v.fail("required", "missing required property "+strconv.Quote(name))
+		}
+	}
+
+	for _, dep := range s.dependentRequired {
+		if node.Dig(dep.name) == nil {
+			continue
+		}
+		for _, name := range dep.required {
+			if node.Dig(name) == nil {
+				v.fail("dependentRequired", "property "+strconv.Quote(name)+" is required by "+strconv.Quote(dep.name))
+			}
+		}
+	}
+
+	if s.propertyNames != nil {
+		v.validateNames(s.propertyNames, fields)
+	}
+
+	for _, field := range fields {
+		name := field.AsString()
+		value := field.AsFieldValue()
+
+		matched := false
+		for i := range s.properties {
+			if s.properties[i].name == name {
+				matched = true
+				v.push(name)
+				v.validate(s.properties[i].schema, value)
+				v.pop()
+				break
+			}
+		}
+
+		for i := range s.patternProperties {
+			if s.patternProperties[i].pattern.MatchString(name) {
+				matched = true
+				v.push(name)
+				v.validate(s.patternProperties[i].schema, value)
+				v.pop()
+			}
+		}
+
+		if matched || s.additional == nil {
+			continue
+		}
+
+		if s.additional.isBool && !s.additional.boolean {
+			v.fail("additionalProperties", "additional property "+strconv.Quote(name)+" isn't allowed")
+			continue
+		}
+
+		v.push(name)
+		v.validate(s.additional, value)
+		v.pop()
+	}
+}
+
+// validateNames validates field names as standalone strings
+func (v *validator) validateNames(s *Schema, fields []*insaneJSON.Node) {
+	if v.names == nil {
+		v.names = insaneJSON.Spawn()
+	}
+
+	for _, field := range fields {
+		name := field.AsString()
+		l := len(v.errors)
+		v.validate(s, v.names.MutateToString(name))
+		for _, err := range v.errors[l:] {
+			err.Keyword = "propertyNames"
+			err.Message = "property name " + strconv.Quote(name) + ": " + err.Message
+		}
+	}
+}
+
+func (v *validator) validateArray(s *Schema, node *insaneJSON.Node) {
+	elements := node.AsArray()
+
+	if s.minItems != -1 && len(elements) < s.minItems {
+		v.fail("minItems", "array should have at least "+strconv.Itoa(s.minItems)+" items")
+	}
+	if s.maxItems != -1 && len(elements) > s.maxItems {
+		v.fail("maxItems", "array should have at most "+strconv.Itoa(s.maxItems)+" items")
+	}
+
+	for i, element := range elements {
+		sub := s.items
+		if i < len(s.prefixItems) {
+			sub = s.prefixItems[i]
+		}
+		if sub == nil {
+			continue
+		}
+
+		v.push(strconv.Itoa(i))
+		v.validate(sub, element)
+		v.pop()
+	}
+
+	if s.contains != nil {
+		count := 0
+		for _, element := range elements {
+			if v.try(s.contains, element) {
+				count++
+			}
+		}
+
+		minContains := 1
+		if s.minContains != -1 {
+			minContains = s.minContains
+		}
+		if count < minContains {
+			v.fail("contains", "array should contain at least "+strconv.Itoa(minContains)+" matching items")
+		}
+		if s.maxContains != -1 && count > s.maxContains {
+			v.fail("maxContains", "array should contain at most "+strconv.Itoa(s.maxContains)+" matching items")
+		}
+	}
+
+	if s.uniqueItems {
+		for i := 1; i < len(elements); i++ {
+			for j := 0; j < i; j++ {
+				if equal(elements[i], elements[j]) {
+					v.fail("uniqueItems", "items "+strconv.Itoa(j)+" and "+strconv.Itoa(i)+" are equal")
+					return
+				}
+			}
+		}
+	}
+}
+
+func (v *validator) validateNumber(s *Schema, node *insaneJSON.Node) {
+	x, ok := parseDecimal(string(node.AsNumber()))
+
+	if s.minimum != nil && (!ok || x.cmp(s.minimum.value) < 0) {
+		v.fail("minimum", "value should be >= "+s.minimum.text)
+	}
+	if s.maximum != nil && (!ok || x.cmp(s.maximum.value) > 0) {
+		v.fail("maximum", "value should be <= "+s.maximum.text)
+	}
+	if s.exclusiveMinimum != nil && (!ok || x.cmp(s.exclusiveMinimum.value) <= 0) {
+		v.fail("exclusiveMinimum", "value should be > "+s.exclusiveMinimum.text)
+	}
+	if s.exclusiveMaximum != nil && (!ok || x.cmp(s.exclusiveMaximum.value) >= 0) {
+		v.fail("exclusiveMaximum", "value should be < "+s.exclusiveMaximum.text)
+	}
+	if s.multipleOf != nil && (!ok || !x.isMultipleOf(s.multipleOf.value)) {
+		v.fail("multipleOf", "value should be a multiple of "+s.multipleOf.text)
+	}
+}
+
+func (v *validator) validateString(s *Schema, node *insaneJSON.Node) {
+	if s.minLength == -1 && s.maxLength == -1 && s.pattern == nil && s.format == "" {
+		return
+	}
+
+	value := node.AsString()
+
+	if s.minLength != -1 || s.maxLength != -1 {
+		l := utf8.RuneCountInString(value)
+		if s.minLength != -1 && l < s.minLength {
+			v.fail("minLength", "length should be >= "+strconv.Itoa(s.minLength))
+		}
+		if s.maxLength != -1 && l > s.maxLength {
+			v.fail("maxLength", "length should be <= "+strconv.Itoa(s.maxLength))
+		}
+	}
+
+	if s.pattern != nil && !s.pattern.MatchString(value) {
+		v.fail("pattern", "value should match pattern "+strconv.Quote(s.pattern.String()))
+	}
+
+	if s.format != "" {
+		if check, has := formats[s.format]; has && !check(value) {
+			v.fail("format", "value isn't a valid "+strconv.Quote(s.format))
+		}
+	}
+}
+
+func (v *validator) validateCombinators(s *Schema, node *insaneJSON.Node) {
+	for _, sub := range s.allOf {
+		v.validate(sub, node)
+	}
+
+	if s.anyOf != nil {
+		matched := false
+		for _, sub := range s.anyOf {
+			if v.try(sub, node) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail("anyOf", "value doesn't match any schema of anyOf")
+		}
+	}
+
+	if s.oneOf != nil {
+		count := 0
+		for _, sub := range s.oneOf {
+			if v.try(sub, node) {
+				count++
+			}
+		}
+		if count == 0 {
+			v.fail("oneOf", "value doesn't match any schema of oneOf")
+		}
+		if count > 1 {
+			v.fail("oneOf", "value matches "+strconv.Itoa(count)+" schemas of oneOf, exactly one is expected")
+		}
+	}
+
+	if s.not != nil && v.try(s.not, node) {
+		v.fail("not", "value shouldn't match the schema of not")
+	}
+
+	if s.cond != nil {
+		if v.try(s.cond, node) {
+			if s.then != nil {
+				v.validate(s.then, node)
+			}
+		} else if s.els != nil {
+			v.validate(s.els, node)
+		}
+	}
+}
+
+// try validates the node and drops errors, it's used by combinators
+func (v *validator) try(s *Schema, node *insaneJSON.Node) bool {
+	l := len(v.errors)
+	v.validate(s, node)
+	ok := len(v.errors) == l
+	v.errors = v.errors[:l]
+
+	return ok
+}
+
+func (v *validator) push(token string) {
+	v.path = append(v.path, token)
+}
+
+func (v *validator) pop() {
+	v.path = v.path[:len(v.path)-1]
+}
+
+func (v *validator) fail(keyword string, message string) {
+	b := strings.Builder{}
+	for _, token := range v.path {
+		b.WriteByte('/')
+		b.WriteString(escapeToken(token))
+	}
+
+	v.errors = append(v.errors, &Error{Path: b.String(), Keyword: keyword, Message: message})
+}
+
+func matchType(types int, node *insaneJSON.Node) bool {
+	switch {
+	case node.IsNull():
+		return types&typeNull != 0
+	case node.IsTrue() || node.IsFalse():
+		return types&typeBoolean != 0
+	case node.IsObject():
+		return types&typeObject != 0
+	case node.IsArray():
+		return types&typeArray != 0
+	case node.IsString():
+		return types&typeString != 0
+	case node.IsNumber():
+		return types&typeNumber != 0 || (types&typeInteger != 0 && isInteger(node))
+	}
+
+	return false
+}
+
+func isInteger(node *insaneJSON.Node) bool {
+	data := string(node.AsNumber())
+	if strings.IndexAny(data, ".eE") == -1 {
+		return true
+	}
+
+	d, ok := parseDecimal(data)
+	return ok && d.isInteger()
+}
+
+func nodeType(node *insaneJSON.Node) string {
+	switch {
+	case node.IsNull():
+		return "null"
+	case node.IsTrue() || node.IsFalse():
+		return "boolean"
+	case node.IsObject():
+		return "object"
+	case node.IsArray():
+		return "array"
+	case node.IsString():
+		return "string"
+	case node.IsNumber():
+		return "number"
+	}
+
+	return "nothing"
+}
+
+func typesString(types int) string {
+	names := make([]string, 0, len(typeNames))
+	for _, name := range typeOrder {
+		if types&typeNames[name] != 0 {
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, " or ")
+}
+
+// equal compares nodes as JSON values, numbers are equal if they have the same value: 1 == 1.0
+func equal(a, b *insaneJSON.Node) bool {
+	switch {
+	case a.IsNull():
+		return b.IsNull()
+	case a.IsTrue():
+		return b.IsTrue()
+	case a.IsFalse():
+		return b.IsFalse()
+	case a.IsString():
+		return b.IsString() && a.AsString() == b.AsString()
+	case a.IsNumber():
+		if !b.IsNumber() {
+			return false
+		}
+		x, y := string(a.AsNumber()), string(b.AsNumber())
+		if x == y {
+			return true
+		}
+		dx, okX := parseDecimal(x)
+		dy, okY := parseDecimal(y)
+		return okX && okY && dx == dy
+	case a.IsArray():
+		if !b.IsArray() || len(a.AsArray()) != len(b.AsArray()) {
+			return false
+		}
+		elements := b.AsArray()
+		for i, element := range a.AsArray() {
+			if !equal(element, elements[i]) {
+				return false
+			}
+		}
+		return true
+	case a.IsObject():
+		if !b.IsObject() || len(a.AsFields()) != len(b.AsFields()) {
+			return false
+		}
+		for _, field := range a.AsFields() {
+			if !equal(field.AsFieldValue(), b.Dig(field.AsString())) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
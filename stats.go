@@ -0,0 +1,84 @@
+package insaneJSON
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+var poolExpansions uint64
+
+/*
+PoolStats is a snapshot of the decoder pool memory usage.
+Node capacity and buffer bytes of decoders which are in use right now
+are reported as they were at the moment of the last Spawn()/Release().
+*/
+type PoolStats struct {
+	DecodersInUse   int
+	DecodersIdle    int
+	DecodersCreated int
+	NodesCap        int
+	BufBytes        int
+	PoolExpansions  uint64
+}
+
+/*
+Metric is a single value of PoolStats prepared for exporting.
+Kind is either "gauge" or "counter", names follow prometheus conventions.
+*/
+type Metric struct {
+	Name  string
+	Help  string
+	Kind  string
+	Value float64
+}
+
+// Stats returns memory statistics of all decoders in the pool
+func Stats() PoolStats {
+	decoderPoolMu.Lock()
+	defer decoderPoolMu.Unlock()
+
+	stats := PoolStats{
+		DecodersInUse:   decoderPoolIndex + 1,
+		DecodersIdle:    len(decoderPool) - decoderPoolIndex - 1,
+		DecodersCreated: len(decoderPool),
+		PoolExpansions:  atomic.LoadUint64(&poolExpansions),
+	}
+
+	for _, d := range decoderPool {
+		stats.NodesCap += d.statNodesCap
+		stats.BufBytes += d.statBufCap
+	}
+
+	return stats
+}
+
+// Metrics returns stats as a list of metrics, useful to export them without any dependencies
+func (s PoolStats) Metrics() []Metric {
+	return []Metric{
+		{Name: "insane_json_decoders_in_use", Help: "Decoders taken from the pool.", Kind: "gauge", Value: float64(s.DecodersInUse)},
+		{Name: "insane_json_decoders_idle", Help: "Decoders waiting in the pool.", Kind: "gauge", Value: float64(s.DecodersIdle)},
+		{Name: "insane_json_decoders_created_total", Help: "Decoders created since start.", Kind: "counter", Value: float64(s.DecodersCreated)},
+		{Name: "insane_json_nodes_capacity", Help: "Nodes held by node pools of all decoders.", Kind: "gauge", Value: float64(s.NodesCap)},
+		{Name: "insane_json_buf_bytes", Help: "Bytes retained by internal buffers of all decoders.", Kind: "gauge", Value: float64(s.BufBytes)},
+		{Name: "insane_json_pool_expansions_total", Help: "Node pool expansions since start.", Kind: "counter", Value: float64(s.PoolExpansions)},
+	}
+}
+
+// WritePrometheus writes stats in prometheus text exposition format
+func (s PoolStats) WritePrometheus(w io.Writer) error {
+	for _, m := range s.Metrics() {
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", m.Name, m.Help, m.Name, m.Kind, m.Name, m.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateStats should be called under decoderPoolMu
+func (d *decoder) updateStats() {
+	d.statNodesCap = len(d.nodePool)
+	d.statBufCap = cap(d.buf)
+}
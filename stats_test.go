@@ -0,0 +1,43 @@
+package insaneJSON
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	before := Stats()
+
+	root, err := DecodeString(`{"a":"b"}`)
+	assert.NoError(t, err, "error while decoding")
+
+	stats := Stats()
+	assert.Equal(t, before.DecodersInUse+1, stats.DecodersInUse, "wrong decoders in use")
+	assert.True(t, stats.DecodersCreated >= stats.DecodersInUse, "wrong decoders created")
+	assert.True(t, stats.NodesCap >= StartNodePoolSize, "wrong nodes capacity")
+
+	// the pool may be already expanded by other tests
+	depth := len(root.decoder.nodePool) * 2
+	json := strings.Repeat(`[`, depth) + strings.Repeat(`]`, depth)
+	err = root.DecodeString(json)
+	assert.NoError(t, err, "error while decoding")
+	Release(root)
+
+	stats = Stats()
+	assert.Equal(t, before.DecodersInUse, stats.DecodersInUse, "wrong decoders in use")
+	assert.True(t, stats.PoolExpansions > before.PoolExpansions, "pool expansions should be counted")
+	assert.True(t, stats.BufBytes >= len(json), "wrong buf bytes")
+	assert.Equal(t, stats.DecodersCreated, stats.DecodersInUse+stats.DecodersIdle, "wrong decoders count")
+}
+
+func TestStatsWritePrometheus(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := PoolStats{DecodersInUse: 3, PoolExpansions: 5}.WritePrometheus(out)
+	assert.NoError(t, err, "error while writing")
+
+	assert.Contains(t, out.String(), "# TYPE insane_json_decoders_in_use gauge\ninsane_json_decoders_in_use 3\n", "wrong metric")
+	assert.Contains(t, out.String(), "# TYPE insane_json_pool_expansions_total counter\ninsane_json_pool_expansions_total 5\n", "wrong metric")
+}
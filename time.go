@@ -0,0 +1,189 @@
+package insaneJSON
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Special layouts for AsTime()/MutateToTime() which represent time as a unix epoch number
+const (
+	LayoutUnix      = "unix"
+	LayoutUnixMilli = "unixmilli"
+	LayoutUnixMicro = "unixmicro"
+	LayoutUnixNano  = "unixnano"
+
+	// numbers less than these are treated as seconds, millis and micros, others are nanos
+	epochSecondsLimit = 1e11
+	epochMillisLimit  = 1e14
+	epochMicrosLimit  = 1e17
+)
+
+// DefaultTimeLayouts are used by AsTime() if no layouts are provided, RFC3339 is also parsed by RFC3339Nano
+var DefaultTimeLayouts = []string{time.RFC3339Nano}
+
+/*
+AsTime converts string node using provided layouts or DefaultTimeLayouts.
+Numbers and numeric strings which don't match layouts are unix epochs,
+unit is detected by magnitude: seconds, millis, micros or nanos.
+Use LayoutUnix* to set the unit explicitly.
+*/
+func (n *Node) AsTime(layouts ...string) (time.Time, error) {
+	n.debugCheck()
+
+	if !n.hasValue() || n.bits&(hellBitTrue|hellBitFalse) != 0 {
+		return time.Time{}, ErrNotTime
+	}
+
+	s := n.AsString()
+	if len(layouts) == 0 {
+		layouts = DefaultTimeLayouts
+	}
+
+	for _, layout := range layouts {
+		if unit := epochUnit(layout); unit != 0 {
+			if t, ok := parseEpoch(s, unit); ok {
+				return t, nil
+			}
+			continue
+		}
+
+		if n.bits&hellBitNumber == hellBitNumber {
+			continue
+		}
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	if t, ok := parseEpoch(s, 0); ok {
+		return t, nil
+	}
+
+	return time.Time{}, ErrNotTime
+}
+
+/*
+AsDuration converts strings like "1h30m" using time.ParseDuration(),
+numbers and numeric strings are treated as nanoseconds.
+*/
+func (n *Node) AsDuration() (time.Duration, error) {
+	n.debugCheck()
+
+	if !n.hasValue() || n.bits&(hellBitTrue|hellBitFalse) != 0 {
+		return 0, ErrNotDuration
+	}
+
+	if n.hasNumber() {
+		return time.Duration(n.AsInt64()), nil
+	}
+
+	d, err := time.ParseDuration(n.AsString())
+	if err != nil {
+		return 0, ErrNotDuration
+	}
+
+	return d, nil
+}
+
+// MutateToTime formats time with layout, LayoutUnix* layouts produce numbers
+func (n *Node) MutateToTime(t time.Time, layout string) *Node {
+	n.debugCheck()
+
+	if n == nil || n.bits&hellBitField == hellBitField {
+		return n
+	}
+
+	switch epochUnit(layout) {
+	case time.Second:
+		return n.MutateToInt64(t.Unix())
+	case time.Millisecond:
+		return n.MutateToInt64(t.UnixMilli())
+	case time.Microsecond:
+		return n.MutateToInt64(t.UnixMicro())
+	case time.Nanosecond:
+		return n.MutateToInt64(t.UnixNano())
+	}
+
+	return n.MutateToString(t.Format(layout))
+}
+
+// MutateToDuration uses time.Duration.String() format, e.g. "1h30m0s"
+func (n *Node) MutateToDuration(d time.Duration) *Node {
+	n.debugCheck()
+
+	return n.MutateToString(d.String())
+}
+
+func epochUnit(layout string) time.Duration {
+	switch layout {
+	case LayoutUnix:
+		return time.Second
+	case LayoutUnixMilli:
+		return time.Millisecond
+	case LayoutUnixMicro:
+		return time.Microsecond
+	case LayoutUnixNano:
+		return time.Nanosecond
+	default:
+		return 0
+	}
+}
+
+// parseEpoch parses numbers like "1571498062" or "1571498062.123", unit is detected by magnitude if it's zero
+func parseEpoch(s string, unit time.Duration) (time.Time, bool) {
+	if !isNumber(s) || strings.IndexAny(s, "eE") != -1 {
+		return time.Time{}, false
+	}
+
+	intPart := s
+	fracPart := ""
+	if i := strings.IndexByte(s, '.'); i != -1 {
+		intPart = s[:i]
+		fracPart = s[i+1:]
+	}
+
+	value, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	if unit == 0 {
+		abs := value
+		if abs < 0 {
+			abs = -abs
+		}
+		switch {
+		case abs < epochSecondsLimit:
+			unit = time.Second
+		case abs < epochMillisLimit:
+			unit = time.Millisecond
+		case abs < epochMicrosLimit:
+			unit = time.Microsecond
+		default:
+			unit = time.Nanosecond
+		}
+	}
+
+	// fraction of unit, only nanoseconds matter
+	frac := int64(0)
+	if len(fracPart) > 9 {
+		fracPart = fracPart[:9]
+	}
+	if len(fracPart) > 0 {
+		x, _ := strconv.ParseInt(fracPart, 10, 64)
+		for i := len(fracPart); i < 9; i++ {
+			x *= 10
+		}
+		frac = x * int64(unit) / int64(time.Second)
+		if s[0] == '-' {
+			frac = -frac
+		}
+	}
+
+	perSecond := int64(time.Second / unit)
+	sec := value / perSecond
+	nsec := (value%perSecond)*int64(unit) + frac
+
+	return time.Unix(sec, nsec), true
+}
@@ -0,0 +1,90 @@
+package insaneJSON
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsTime(t *testing.T) {
+	expected := time.Date(2019, 10, 19, 15, 54, 22, 312000000, time.UTC)
+
+	tests := []struct {
+		json    string
+		layouts []string
+		result  time.Time
+		err     error
+	}{
+		{json: `"2019-10-19T15:54:22.312Z"`, result: expected},
+		{json: `"2019-10-19T15:54:22Z"`, result: expected.Truncate(time.Second)},
+		{json: `1571500462`, result: expected.Truncate(time.Second)},
+		{json: `1571500462.312`, result: expected},
+		{json: `"1571500462312"`, result: expected},
+		{json: `1571500462312000`, result: expected},
+		{json: `1571500462312000000`, result: expected},
+		{json: `1571500462312`, layouts: []string{LayoutUnixMilli}, result: expected},
+		{json: `1571500462`, layouts: []string{LayoutUnixNano}, result: time.Unix(0, 1571500462)},
+		{json: `"19.10.2019"`, layouts: []string{time.RFC3339, "02.01.2006"}, result: time.Date(2019, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{json: `"20191019"`, layouts: []string{"20060102"}, result: time.Date(2019, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{json: `"insane"`, err: ErrNotTime},
+		{json: `true`, err: ErrNotTime},
+		{json: `{}`, err: ErrNotTime},
+	}
+
+	for _, test := range tests {
+		root, err := DecodeString(test.json)
+		assert.NoError(t, err, "error while decoding")
+
+		result, err := root.AsTime(test.layouts...)
+		assert.Equal(t, test.err, err, "wrong error for %s", test.json)
+		assert.True(t, test.result.Equal(result), "wrong time for %s: %s", test.json, result)
+		Release(root)
+	}
+}
+
+func TestAsDuration(t *testing.T) {
+	root, err := DecodeString(`{"str":"1h30m","num":1500,"num_str":"2000","bad":"insane"}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	d, err := root.Dig("str").AsDuration()
+	assert.NoError(t, err, "duration should be parsed")
+	assert.Equal(t, 90*time.Minute, d, "wrong duration")
+
+	d, err = root.Dig("num").AsDuration()
+	assert.NoError(t, err, "duration should be parsed")
+	assert.Equal(t, 1500*time.Nanosecond, d, "wrong duration")
+
+	d, err = root.Dig("num_str").AsDuration()
+	assert.NoError(t, err, "duration should be parsed")
+	assert.Equal(t, 2000*time.Nanosecond, d, "wrong duration")
+
+	_, err = root.Dig("bad").AsDuration()
+	assert.Equal(t, ErrNotDuration, err, "wrong error")
+
+	_, err = root.Dig("missing").AsDuration()
+	assert.Equal(t, ErrNotDuration, err, "wrong error")
+}
+
+func TestMutateToTime(t *testing.T) {
+	root, err := DecodeString(`{"a":"a","b":"b","c":"c"}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	ts := time.Date(2019, 10, 19, 15, 54, 22, 312000000, time.UTC)
+	root.Dig("a").MutateToTime(ts, time.RFC3339Nano)
+	root.Dig("b").MutateToTime(ts, LayoutUnixMilli)
+	root.Dig("c").MutateToDuration(90 * time.Second)
+
+	assert.Equal(t, `{"a":"2019-10-19T15:54:22.312Z","b":1571500462312,"c":"1m30s"}`, root.EncodeToString(), "wrong result json")
+
+	result, err := root.Dig("b").AsTime()
+	assert.NoError(t, err, "time should be parsed")
+	assert.True(t, ts.Equal(result), "wrong time")
+
+	// nanoseconds overflow int64 after 2262 year
+	far := time.Date(2500, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, int64(16725225600000), root.Dig("b").MutateToTime(far, LayoutUnixMilli).AsInt64(), "wrong milliseconds")
+	assert.Equal(t, int64(16725225600000000), root.Dig("b").MutateToTime(far, LayoutUnixMicro).AsInt64(), "wrong microseconds")
+}
@@ -0,0 +1,75 @@
+package insaneJSON
+
+/*
+TrimPolicy describes when Release() shrinks memory of a pooled Root.
+Zero value of any limit disables it, so zero value of TrimPolicy means
+that memory is never trimmed automatically, which is the default.
+Set ReleaseTrimPolicy before decoding starts, it isn't safe to change it concurrently.
+*/
+type TrimPolicy struct {
+	// MaxBufBytes is an absolute ceiling for internal buffer capacity
+	MaxBufBytes int
+	// MaxNodes is an absolute ceiling for node pool size
+	MaxNodes int
+	// UsageFactor shrinks memory if capacity is more than UsageFactor times
+	// the moving average of usage, so a single huge JSON doesn't pin memory forever
+	UsageFactor int
+}
+
+var (
+	ReleaseTrimPolicy = TrimPolicy{}
+
+	// weight of the latest usage in the moving average
+	trimAvgWeight = 0.125
+)
+
+// trim is called by Release() when decoder is still owned by the caller
+func (d *decoder) trim() {
+	p := &ReleaseTrimPolicy
+	if p.MaxBufBytes == 0 && p.MaxNodes == 0 && p.UsageFactor == 0 {
+		return
+	}
+
+	bufUsage := float64(len(d.buf))
+	nodesUsage := float64(d.nodeCount)
+	if d.avgBuf == 0 && d.avgNodes == 0 {
+		d.avgBuf = bufUsage
+		d.avgNodes = nodesUsage
+	} else {
+		d.avgBuf += (bufUsage - d.avgBuf) * trimAvgWeight
+		d.avgNodes += (nodesUsage - d.avgNodes) * trimAvgWeight
+	}
+
+	bufLimit := p.MaxBufBytes
+	nodesLimit := p.MaxNodes
+	if p.UsageFactor != 0 {
+		bufLimit = minLimit(bufLimit, int(d.avgBuf)*p.UsageFactor)
+		nodesLimit = minLimit(nodesLimit, int(d.avgNodes)*p.UsageFactor)
+	}
+
+	if bufLimit != 0 && cap(d.buf) > bufLimit {
+		d.buf = make([]byte, 0, bufLimit)
+	}
+
+	// zero means that node pool isn't limited at all
+	if nodesLimit == 0 {
+		return
+	}
+	if nodesLimit < StartNodePoolSize {
+		nodesLimit = StartNodePoolSize
+	}
+	if len(d.nodePool) > nodesLimit {
+		nodePool := make([]*Node, nodesLimit, nodesLimit)
+		copy(nodePool, d.nodePool)
+		d.nodePool = nodePool
+	}
+}
+
+// minLimit returns the least of non zero limits
+func minLimit(a, b int) int {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+
+	return a
+}
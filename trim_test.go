@@ -0,0 +1,80 @@
+package insaneJSON
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrimPolicyCeiling(t *testing.T) {
+	ReleaseTrimPolicy = TrimPolicy{MaxBufBytes: 1024, MaxNodes: 256}
+	defer func() { ReleaseTrimPolicy = TrimPolicy{} }()
+
+	root := Spawn()
+	d := root.decoder
+
+	json := `[` + strings.Repeat(`"insane",`, 1000) + `"insane"]`
+	err := root.DecodeString(json)
+	assert.NoError(t, err, "error while decoding")
+	assert.True(t, root.BuffCap() > 1024, "buffer should grow")
+	assert.True(t, root.PoolSize() > 256, "pool should grow")
+
+	Release(root)
+
+	assert.True(t, cap(d.buf) <= 1024, "buffer should be trimmed")
+	assert.Equal(t, 256, len(d.nodePool), "pool should be trimmed")
+}
+
+func TestTrimPolicyBufOnly(t *testing.T) {
+	ReleaseTrimPolicy = TrimPolicy{MaxBufBytes: 1024}
+	defer func() { ReleaseTrimPolicy = TrimPolicy{} }()
+
+	root := Spawn()
+	d := root.decoder
+
+	json := `[` + strings.Repeat(`"insane",`, 1000) + `"insane"]`
+	err := root.DecodeString(json)
+	assert.NoError(t, err, "error while decoding")
+	poolSize := root.PoolSize()
+	assert.True(t, poolSize > StartNodePoolSize, "pool should grow")
+
+	Release(root)
+
+	assert.True(t, cap(d.buf) <= 1024, "buffer should be trimmed")
+	assert.Equal(t, poolSize, len(d.nodePool), "pool shouldn't be trimmed without node limit")
+}
+
+func TestTrimPolicyUsageFactor(t *testing.T) {
+	ReleaseTrimPolicy = TrimPolicy{UsageFactor: 4}
+	defer func() { ReleaseTrimPolicy = TrimPolicy{} }()
+
+	small := `{"a":"b","c":"d"}`
+	huge := `[` + strings.Repeat(`"insane",`, 1000) + `"insane"]`
+
+	root := Spawn()
+	d := root.decoder
+	d.avgBuf, d.avgNodes = 0, 0
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, root.DecodeString(small), "error while decoding")
+		d.trim()
+	}
+
+	assert.NoError(t, root.DecodeString(huge), "error while decoding")
+	d.trim()
+	assert.True(t, root.BuffCap() >= len(huge)/4, "single huge JSON shouldn't collapse the buffer")
+
+	for i := 0; i < 50; i++ {
+		assert.NoError(t, root.DecodeString(small), "error while decoding")
+		d.trim()
+	}
+	Release(root)
+
+	assert.True(t, cap(d.buf) < len(huge)/8, "buffer should be trimmed")
+	assert.Equal(t, StartNodePoolSize, len(d.nodePool), "pool should be trimmed")
+
+	root, err := DecodeString(huge)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+	assert.Equal(t, huge, root.EncodeToString(), "wrong encoding")
+}
@@ -0,0 +1,92 @@
+package insaneJSON
+
+import (
+	"strconv"
+)
+
+/*
+Upsert digs the path and creates missing nodes, so the result is ready for Mutate*() call.
+Missing object fields are created, null nodes are converted to objects
+or to arrays if the next path segment is an array index, which is a non negative number without a sign.
+Arrays are extended up to the index, gaps are filled with nulls, checkout UpsertPadded().
+Returns nil if the path goes through a scalar value, uses wrong array index
+or the index needs more than MaxArrayPadding gap elements.
+Nodes are taken from the root pool, root can be nil, nodes are allocated then.
+*/
+func (n *Node) Upsert(root *Root, path ...string) *Node {
+	n.debugCheck()
+
+	return n.upsert(root, "null", path)
+}
+
+// UpsertPadded is the same as Upsert(), but uses padding JSON to fill gaps of arrays
+func (n *Node) UpsertPadded(root *Root, padding string, path ...string) *Node {
+	n.debugCheck()
+
+	if root == nil {
+		return nil
+	}
+
+	return n.upsert(root, padding, path)
+}
+
+func (n *Node) upsert(root *Root, padding string, path []string) *Node {
+	node := n
+	for _, segment := range path {
+		if node == nil {
+			return nil
+		}
+
+		if node.bits&hellBitNull == hellBitNull {
+			if index, ok := upsertIndex(segment); ok {
+				if index > MaxArrayPadding {
+					return nil
+				}
+				node.MutateToArray()
+			} else {
+				node.MutateToObject()
+			}
+		}
+
+		switch node.bits & hellBitTypeFilter {
+		case hellBitObject:
+			child := node.Dig(segment)
+			if child == nil {
+				child = node.AddFieldNoAlloc(root, segment)
+			}
+			node = child
+		case hellBitArray:
+			index, ok := upsertIndex(segment)
+			if !ok || index-len(node.nodes) > MaxArrayPadding {
+				return nil
+			}
+
+			for len(node.nodes) <= index {
+				element := node.AddElementNoAlloc(root)
+				if len(node.nodes) <= index && padding != "null" {
+					element.MutateToJSON(root, padding)
+				}
+			}
+			node = node.nodes[index]
+		default:
+			return nil
+		}
+	}
+
+	return node
+}
+
+// upsertIndex parses array index, unlike strconv.Atoi() it accepts digits only, so "-1" and "+1" aren't indexes
+func upsertIndex(segment string) (int, bool) {
+	if segment == "" {
+		return 0, false
+	}
+	for i := 0; i < len(segment); i++ {
+		if segment[i] < '0' || segment[i] > '9' {
+			return 0, false
+		}
+	}
+
+	index, err := strconv.Atoi(segment)
+	return index, err == nil
+}
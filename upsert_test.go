@@ -0,0 +1,98 @@
+package insaneJSON
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpsert(t *testing.T) {
+	root, err := DecodeString(`{}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.Upsert(root, "a", "b", "c").MutateToInt(1)
+	assert.Equal(t, `{"a":{"b":{"c":1}}}`, root.EncodeToString(), "wrong result json")
+
+	root.Upsert(root, "a", "b", "d").MutateToString("e")
+	assert.Equal(t, `{"a":{"b":{"c":1,"d":"e"}}}`, root.EncodeToString(), "wrong result json")
+
+	root.Upsert(root, "a", "b", "c").MutateToInt(2)
+	assert.Equal(t, `{"a":{"b":{"c":2,"d":"e"}}}`, root.EncodeToString(), "wrong result json")
+
+	root.Upsert(root, "x", "2", "y").MutateToBool(true)
+	assert.Equal(t, `{"a":{"b":{"c":2,"d":"e"}},"x":[null,null,{"y":true}]}`, root.EncodeToString(), "wrong result json")
+
+	root.Upsert(root, "x", "0").MutateToInt(0)
+	assert.Equal(t, `{"a":{"b":{"c":2,"d":"e"}},"x":[0,null,{"y":true}]}`, root.EncodeToString(), "wrong result json")
+
+	assert.Equal(t, root.Node, root.Upsert(root), "wrong result node")
+	assert.Nil(t, root.Upsert(root, "a", "b", "c", "d"), "path through scalar should fail")
+	assert.Nil(t, root.Upsert(root, "x", "-1"), "negative index should fail")
+	assert.Nil(t, root.Upsert(root, "x", "y"), "non index segment for array should fail")
+	assert.Equal(t, `{"a":{"b":{"c":2,"d":"e"}},"x":[0,null,{"y":true}]}`, root.EncodeToString(), "failed upserts shouldn't change json")
+}
+
+func TestUpsertExisting(t *testing.T) {
+	root, err := DecodeString(`{"a":null,"b":[1],"1":{"2":"3"}}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.Upsert(root, "a", "1").MutateToString("x")
+	root.Upsert(root, "b", "2").MutateToInt(3)
+	root.Upsert(root, "1", "2").MutateToString("4")
+	assert.Equal(t, `{"a":[null,"x"],"b":[1,null,3],"1":{"2":"4"}}`, root.EncodeToString(), "wrong result json")
+	assert.Equal(t, "x", root.Dig("a", "1").AsString(), "wrong result")
+	assert.Equal(t, 3, root.Dig("b", "2").AsInt(), "wrong result")
+}
+
+func TestUpsertSigned(t *testing.T) {
+	root, err := DecodeString(`{"a":null,"b":[1]}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	assert.Nil(t, root.Upsert(root, "b", "+0"), "signed index should fail")
+	assert.Nil(t, root.Upsert(root, "b", "-1"), "negative index should fail")
+	assert.Equal(t, `{"a":null,"b":[1]}`, root.EncodeToString(), "failed upserts shouldn't change json")
+
+	root.Upsert(root, "a", "-1").MutateToInt(1)
+	assert.Equal(t, `{"a":{"-1":1},"b":[1]}`, root.EncodeToString(), "signed segment should create a field")
+}
+
+func TestUpsertHugeIndex(t *testing.T) {
+	root, err := DecodeString(`{"a":null,"b":[1]}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	assert.Nil(t, root.Upsert(root, "a", "100000"), "huge index should fail")
+	assert.Nil(t, root.Upsert(root, "b", "100000"), "huge index should fail")
+	assert.Nil(t, root.Upsert(root, "c", "100000"), "huge index should fail")
+	assert.Equal(t, `{"a":null,"b":[1],"c":null}`, root.EncodeToString(), "failed upserts shouldn't change arrays")
+
+	assert.NotNil(t, root.Upsert(root, "b", strconv.Itoa(MaxArrayPadding+1)), "padding up to the limit should be added")
+	assert.Equal(t, MaxArrayPadding+2, len(root.Dig("b").AsArray()), "wrong length")
+}
+
+func TestUpsertPadded(t *testing.T) {
+	root, err := DecodeString(`{"a":[]}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.UpsertPadded(root, `{"p":0}`, "a", "2", "b").MutateToInt(1)
+	assert.Equal(t, `{"a":[{"p":0},{"p":0},{"b":1}]}`, root.EncodeToString(), "wrong result json")
+
+	root.Dig("a", "0", "p").MutateToInt(5)
+	assert.Equal(t, `{"a":[{"p":5},{"p":0},{"b":1}]}`, root.EncodeToString(), "padding nodes shouldn't be shared")
+
+	assert.Nil(t, root.UpsertPadded(nil, `0`, "a", "5"), "padding requires root")
+}
+
+func TestUpsertNoRoot(t *testing.T) {
+	root, err := DecodeString(`{}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.Upsert(nil, "a", "1").MutateToInt(1)
+	assert.Equal(t, `{"a":[null,1]}`, root.EncodeToString(), "wrong result json")
+}
@@ -0,0 +1,142 @@
+package insaneJSON
+
+import (
+	"strconv"
+	"sync"
+)
+
+// WalkAction tells Walk() what to do after a node is visited
+type WalkAction int
+
+const (
+	// WalkContinue visits children of the node and then its siblings
+	WalkContinue WalkAction = iota
+	// WalkSkipChildren skips children of the node, but visits its siblings
+	WalkSkipChildren
+	// WalkStop stops walking
+	WalkStop
+)
+
+/*
+WalkFunc is called for every node by Walk().
+Path is reused between calls, so copy it if it's needed after the call.
+Object fields are represented with field names, array elements with indexes.
+*/
+type WalkFunc func(path []string, node *Node) WalkAction
+
+const walkIndexesCount = 1024
+
+var (
+	walkPathPool = sync.Pool{New: func() interface{} {
+		path := make([]string, 0, 16)
+		return &path
+	}}
+
+	// preallocated array indexes to keep walking allocation free
+	walkIndexes = make([]string, walkIndexesCount)
+)
+
+func init() {
+	for i := range walkIndexes {
+		walkIndexes[i] = strconv.Itoa(i)
+	}
+}
+
+/*
+Walk visits node and all its children in depth first order.
+It's safe to delete current node with Suicide() or rename it using FieldNode().MutateToField(),
+but other nodes of the same object or array shouldn't be added or deleted during the walk.
+*/
+func (n *Node) Walk(fn WalkFunc) {
+	n.debugCheck()
+
+	if n == nil {
+		return
+	}
+
+	pathBuf := walkPathPool.Get().(*[]string)
+	path := (*pathBuf)[:0]
+
+	if fn(path, n) == WalkContinue {
+		path, _ = n.walk(path, fn)
+	}
+
+	*pathBuf = path[:0]
+	walkPathPool.Put(pathBuf)
+}
+
+// walk returns false if walking should be stopped
+func (n *Node) walk(path []string, fn WalkFunc) ([]string, bool) {
+	isObject := n.bits&hellBitObject == hellBitObject
+	if !isObject && n.bits&hellBitArray != hellBitArray {
+		return path, true
+	}
+
+	for i := 0; i < len(n.nodes); {
+		var child *Node
+		if isObject {
+			field := n.nodes[i]
+			if field.bits&hellBitEscapedField == hellBitEscapedField {
+				field.unescapeField()
+			}
+			child = field.next
+			path = append(path, field.data)
+		} else {
+			child = n.nodes[i]
+			path = append(path, walkIndex(i))
+		}
+
+		action := fn(path, child)
+		if action == WalkStop {
+			return path, false
+		}
+
+		// node is deleted, so another node took its place
+		if i >= len(n.nodes) || (isObject && n.nodes[i].next != child) || (!isObject && n.nodes[i] != child) {
+			path = path[:len(path)-1]
+			continue
+		}
+
+		if action == WalkContinue {
+			ok := false
+			path, ok = child.walk(path, fn)
+			if !ok {
+				return path, false
+			}
+		}
+
+		path = path[:len(path)-1]
+		i++
+	}
+
+	return path, true
+}
+
+// FieldNode returns field node of object value, it's useful to rename the field using MutateToField()
+func (n *Node) FieldNode() *Node {
+	n.debugCheck()
+
+	if n == nil || n.parent == nil || n.parent.bits&hellBitObject != hellBitObject {
+		return nil
+	}
+
+	index := n.actualizeIndex()
+	if index == -1 {
+		return nil
+	}
+
+	field := n.parent.nodes[index]
+	if field.bits&hellBitEscapedField == hellBitEscapedField {
+		field.unescapeField()
+	}
+
+	return field
+}
+
+func walkIndex(i int) string {
+	if i < walkIndexesCount {
+		return walkIndexes[i]
+	}
+
+	return strconv.Itoa(i)
+}
@@ -0,0 +1,91 @@
+package insaneJSON
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	root, err := DecodeString(`{"a":{"b":[1,{"c":"d"}]},"e":[],"f":null}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	paths := make([]string, 0)
+	root.Walk(func(path []string, node *Node) WalkAction {
+		paths = append(paths, strings.Join(path, ".")+"="+node.TypeStr())
+		return WalkContinue
+	})
+
+	expected := []string{
+		"=hellBitObject",
+		"a=hellBitObject",
+		"a.b=hellBitArray",
+		"a.b.0=number",
+		"a.b.1=hellBitObject",
+		"a.b.1.c=string escaped",
+		"e=hellBitArray",
+		"f=null",
+	}
+	assert.Equal(t, expected, paths, "wrong walk order")
+
+	paths = paths[:0]
+	root.Walk(func(path []string, node *Node) WalkAction {
+		paths = append(paths, strings.Join(path, "."))
+		if len(path) == 1 && path[0] == "a" {
+			return WalkSkipChildren
+		}
+		if len(path) == 1 && path[0] == "e" {
+			return WalkStop
+		}
+		return WalkContinue
+	})
+	assert.Equal(t, []string{"", "a", "e"}, paths, "wrong walk order")
+}
+
+func TestWalkMutations(t *testing.T) {
+	root, err := DecodeString(`{"secret":1,"a":{"secret":2,"b":"b","c":"c"},"arr":["secret",{"secret":3},"x","secret","secret"],"secret_too":4,"rename_me":5}`)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	root.Walk(func(path []string, node *Node) WalkAction {
+		if len(path) == 0 {
+			return WalkContinue
+		}
+		name := path[len(path)-1]
+		if strings.HasPrefix(name, "secret") || node.AsString() == "secret" {
+			node.Suicide()
+			return WalkSkipChildren
+		}
+		if name == "rename_me" {
+			node.FieldNode().MutateToField("renamed")
+		}
+		return WalkContinue
+	})
+
+	assert.Equal(t, `{"renamed":5,"a":{"c":"c","b":"b"},"arr":[{},"x"]}`, root.EncodeToString(), "wrong result json")
+	assert.Equal(t, 5, root.Dig("renamed").AsInt(), "field should be renamed")
+}
+
+func TestWalkAllocs(t *testing.T) {
+	root, err := DecodeString(bigJSON)
+	defer Release(root)
+	assert.NoError(t, err, "error while decoding")
+
+	count := 0
+	fn := func(path []string, node *Node) WalkAction {
+		count++
+		return WalkContinue
+	}
+	root.Walk(fn)
+	assert.True(t, count > 0, "nodes should be visited")
+	if raceEnabled {
+		t.Skip("sync.Pool doesn't keep items with -race")
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		root.Walk(fn)
+	})
+	assert.Equal(t, float64(0), allocs, "walk shouldn't allocate")
+}